@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/torresposso/gosmic/pb"
+)
+
+// Options selects what Generate emits.
+type Options struct {
+	Package     string            // Package clause of the generated file
+	Source      string            // Where the schemas came from, for the header
+	Collections []string          // Collections to generate, in output order
+	TypeNames   map[string]string // Collection name -> Go type, overriding the singularized name
+}
+
+// record is the template data for one collection.
+type record struct {
+	Collection string
+	Type       string
+	Fields     []field
+}
+
+type field struct {
+	Name    string // PocketBase field name
+	GoName  string
+	GoType  string
+	Filter  string // pb filter field type, e.g. "TextField"
+	Comment string
+}
+
+// Generate renders the Go source for the requested collections.
+func Generate(schemas []pb.CollectionSchema, opts Options) ([]byte, error) {
+	byName := make(map[string]pb.CollectionSchema, len(schemas))
+	names := make(map[string]string, len(schemas)) // Collection id -> name
+	for _, schema := range schemas {
+		byName[schema.Name] = schema
+		names[schema.ID] = schema.Name
+	}
+
+	var records []record
+	for _, name := range opts.Collections {
+		schema, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("collection %q not found in %s", name, opts.Source)
+		}
+
+		typeName := opts.TypeNames[name]
+		if typeName == "" {
+			typeName = singular(goName(name))
+		}
+		r := record{Collection: name, Type: typeName}
+		for _, f := range schema.Fields {
+			if f.Hidden {
+				continue // Password hashes, token keys: never returned by the API
+			}
+			goType, filter := goType(f)
+			fld := field{Name: f.Name, GoName: goName(f.Name), GoType: goType, Filter: filter}
+			if f.Type == "relation" {
+				target, _ := f.Options["collectionId"].(string)
+				if targetName, ok := names[target]; ok {
+					target = targetName
+				}
+				fld.Comment = "Relation to " + target
+			}
+			r.Fields = append(r.Fields, fld)
+		}
+		records = append(records, r)
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, map[string]any{"Options": opts, "Records": records}); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// goType maps a PocketBase field to its Go type and pb filter field type.
+func goType(f pb.SchemaField) (string, string) {
+	multiple := false
+	if maxSelect, ok := f.Options["maxSelect"].(float64); ok && maxSelect > 1 {
+		multiple = true
+	}
+
+	switch f.Type {
+	case "text", "email", "url", "editor", "password":
+		return "string", "TextField"
+	case "bool":
+		return "bool", "BoolField"
+	case "number":
+		if onlyInt, _ := f.Options["onlyInt"].(bool); onlyInt {
+			return "int", "NumberField"
+		}
+		return "float64", "NumberField"
+	case "date", "autodate":
+		// PocketBase's "2006-01-02 15:04:05.000Z" format is not RFC 3339
+		return "string", "DateField"
+	case "select":
+		if multiple {
+			return "[]string", "Field"
+		}
+		return "string", "TextField"
+	case "relation":
+		if multiple {
+			return "[]string", "RelationField"
+		}
+		return "string", "RelationField"
+	case "file":
+		if multiple {
+			return "[]string", "Field"
+		}
+		return "string", "Field"
+	case "json":
+		return "json.RawMessage", "Field"
+	}
+	return "any", "Field"
+}
+
+// initialisms are kept upper case in Go names, as in CredentialID.
+var initialisms = map[string]bool{"id": true, "url": true, "api": true, "json": true, "html": true, "http": true, "ip": true, "otp": true, "mfa": true}
+
+// goName converts a PocketBase name (snake_case or camelCase) to an exported Go name.
+func goName(name string) string {
+	var words []string
+	start := 0
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			words = append(words, name[start:i])
+			start = i + 1
+		case unicode.IsUpper(r) && i > start:
+			words = append(words, name[start:i])
+			start = i
+		}
+	}
+	words = append(words, name[start:])
+
+	var b strings.Builder
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	if b.Len() == 0 || !unicode.IsLetter(rune(b.String()[0])) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
+
+// singular turns a plural collection name into a record type name.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "ses"), strings.HasSuffix(name, "xes"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+// needsJSON reports whether any record uses json.RawMessage.
+func needsJSON(records []record) bool {
+	for _, r := range records {
+		for _, f := range r.Fields {
+			if strings.Contains(f.GoType, "json.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var fileTemplate = template.Must(template.New("file").Funcs(template.FuncMap{
+	"needsJSON": needsJSON,
+	"quote":     func(s string) string { b, _ := json.Marshal(s); return string(b) },
+}).Parse(`// Code generated by pbgen from {{.Options.Source}}. DO NOT EDIT.
+
+package {{.Options.Package}}
+{{if needsJSON .Records}}
+import "encoding/json"
+{{end}}
+{{range .Records}}{{$r := .}}
+// {{.Type}}Collection is the name of the {{.Collection}} collection.
+const {{.Type}}Collection = {{quote .Collection}}
+
+// {{.Type}} is a record of the {{.Collection}} collection.
+type {{.Type}} struct {
+	CollectionID string ` + "`" + `json:"collectionId,omitempty"` + "`" + `
+{{- range .Fields}}
+	{{.GoName}} {{.GoType}} ` + "`" + `json:{{quote .Name}}` + "`" + `{{if .Comment}} // {{.Comment}}{{end}}
+{{- end}}
+}
+
+// Field names of the {{.Collection}} collection.
+const (
+{{- range .Fields}}
+	{{$r.Type}}Field{{.GoName}} = {{quote .Name}}
+{{- end}}
+)
+
+// {{.Type}}Filter builds filter conditions on the {{.Collection}} collection's fields.
+var {{.Type}}Filter = struct {
+{{- range .Fields}}
+	{{.GoName}} {{.Filter}}
+{{- end}}
+}{
+{{- range .Fields}}
+	{{.GoName}}: {{.Filter}}({{$r.Type}}Field{{.GoName}}),
+{{- end}}
+}
+{{end}}`))
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/pb"
+)
+
+func TestGeneratedRecordsAreUpToDate(t *testing.T) {
+	err := run("../../pb/collections.json", "../../pb/records_gen.go", "pb", defaultCollections, defaultTypes, false, true)
+	assert.NoError(t, err, "run `task generate` after changing pb/collections.json")
+}
+
+func TestRunCheck(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "records_gen.go")
+	schema := filepath.Join(dir, "collections.json")
+	require.NoError(t, os.WriteFile(schema, []byte(`{"page":1,"items":[{"id":"c1","name":"notes","type":"base","fields":[{"id":"f1","name":"body","type":"editor"}]}]}`), 0o644))
+
+	err := run(schema, out, "models", "notes", "", false, true)
+	assert.ErrorContains(t, err, "is out of date")
+
+	require.NoError(t, run(schema, out, "models", "notes", "", false, false))
+	src, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "package models")
+	assert.Contains(t, string(src), "type Note struct")
+	assert.NotContains(t, string(src), "encoding/json")
+
+	assert.NoError(t, run(schema, out, "models", "notes", "", false, true))
+}
+
+func TestGenerate(t *testing.T) {
+	schemas := []pb.CollectionSchema{
+		{ID: "pbc_1", Name: "ships", Fields: []pb.SchemaField{
+			{Name: "id", Type: "text"},
+		}},
+		{ID: "pbc_2", Name: "crew_members", Fields: []pb.SchemaField{
+			{Name: "id", Type: "text"},
+			{Name: "ship", Type: "relation", Options: map[string]any{"collectionId": "pbc_1", "maxSelect": float64(1)}},
+			{Name: "ranks", Type: "select", Options: map[string]any{"maxSelect": float64(3)}},
+			{Name: "age", Type: "number", Options: map[string]any{"onlyInt": true}},
+			{Name: "secret", Type: "text", Hidden: true},
+			{Name: "profile", Type: "json"},
+		}},
+	}
+
+	src, err := Generate(schemas, Options{Package: "pb", Source: "test.json", Collections: []string{"crew_members"}})
+	require.NoError(t, err)
+	code := string(src)
+	assert.Contains(t, code, "// Code generated by pbgen from test.json. DO NOT EDIT.")
+	assert.Contains(t, code, `const CrewMemberCollection = "crew_members"`)
+	assert.Contains(t, code, "Ship         string          `json:\"ship\"` // Relation to ships")
+	assert.Contains(t, code, "Ranks        []string        `json:\"ranks\"`")
+	assert.Contains(t, code, "Age          int             `json:\"age\"`")
+	assert.Contains(t, code, "Profile      json.RawMessage `json:\"profile\"`")
+	assert.Contains(t, code, "CrewMemberFieldShip    = \"ship\"")
+	assert.Contains(t, code, "Ship:    RelationField(CrewMemberFieldShip),")
+	assert.NotContains(t, code, "secret", "hidden fields are never returned")
+
+	_, err = Generate(schemas, Options{Source: "test.json", Collections: []string{"planets"}})
+	assert.EqualError(t, err, `collection "planets" not found in test.json`)
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"id":                   "ID",
+		"emailVisibility":      "EmailVisibility",
+		"credentialId":         "CredentialID",
+		"webauthn_credentials": "WebauthnCredentials",
+		"avatar_url":           "AvatarURL",
+		"2fa":                  "X2fa",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, goName(in), in)
+	}
+}
+
+func TestSingular(t *testing.T) {
+	tests := map[string]string{
+		"Posts":     "Post",
+		"Entries":   "Entry",
+		"Boxes":     "Box",
+		"Addresses": "Address",
+		"Glass":     "Glass",
+		"Crew":      "Crew",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, singular(in), in)
+	}
+}
@@ -0,0 +1,141 @@
+// Command pbgen generates the Go record types of package pb from PocketBase
+// collection schemas: a struct per collection, constants for its field names
+// and typed filter helpers.
+//
+// Schemas are read from a JSON file exported from the PocketBase dashboard
+// (Settings > Export collections). With -live they are fetched from PB_URL as
+// the PB_SUPERUSER_EMAIL / PB_SUPERUSER_PASSWORD superuser and saved to that
+// file first. With -check nothing is written and pbgen fails when the
+// generated file is out of date.
+//
+//	go run ./cmd/pbgen -schema pb/collections.json -out pb/records_gen.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/torresposso/gosmic/pb"
+)
+
+// The app's record types; see pb/records_gen.go.
+const (
+	defaultCollections = "users,posts,webauthn_credentials"
+	defaultTypes       = "webauthn_credentials=WebAuthnCredential"
+)
+
+func main() {
+	var (
+		schemaPath  = flag.String("schema", "pb/collections.json", "exported collections JSON file")
+		out         = flag.String("out", "pb/records_gen.go", "generated Go file")
+		pkg         = flag.String("package", "pb", "package of the generated file")
+		collections = flag.String("collections", defaultCollections, "comma-separated collections to generate")
+		types       = flag.String("types", defaultTypes, "comma-separated collection=Type overrides")
+		live        = flag.Bool("live", false, "fetch the schemas from PB_URL and save them to -schema first")
+		check       = flag.Bool("check", false, "fail if -out is not up to date instead of writing it")
+	)
+	flag.Parse()
+
+	if err := run(*schemaPath, *out, *pkg, *collections, *types, *live, *check); err != nil {
+		fmt.Fprintln(os.Stderr, "pbgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath, out, pkg, collections, types string, live, check bool) error {
+	if live {
+		if err := fetchSchemas(schemaPath); err != nil {
+			return err
+		}
+	}
+
+	schemas, err := loadSchemas(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	opts := Options{
+		Package:     pkg,
+		Source:      filepath.Base(schemaPath),
+		Collections: strings.Split(collections, ","),
+		TypeNames:   map[string]string{},
+	}
+	for _, pair := range strings.Split(types, ",") {
+		if name, typeName, ok := strings.Cut(pair, "="); ok {
+			opts.TypeNames[name] = typeName
+		}
+	}
+
+	src, err := Generate(schemas, opts)
+	if err != nil {
+		return err
+	}
+
+	if check {
+		current, err := os.ReadFile(out)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if !bytes.Equal(current, src) {
+			return fmt.Errorf("%s is out of date with %s; run `task generate`", out, schemaPath)
+		}
+		return nil
+	}
+	return os.WriteFile(out, src, 0o644)
+}
+
+// loadSchemas reads an exported collections file: either a plain array, as
+// exported by the dashboard, or a /api/collections list response.
+func loadSchemas(path string) ([]pb.CollectionSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemas []pb.CollectionSchema
+	if err := json.Unmarshal(data, &schemas); err == nil {
+		return schemas, nil
+	}
+	var list pb.ListResult[pb.CollectionSchema]
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return list.Items, nil
+}
+
+// fetchSchemas saves the collections of the live PocketBase to path.
+func fetchSchemas(path string) error {
+	email, password := os.Getenv("PB_SUPERUSER_EMAIL"), os.Getenv("PB_SUPERUSER_PASSWORD")
+	if email == "" || password == "" {
+		return errors.New("-live needs PB_SUPERUSER_EMAIL and PB_SUPERUSER_PASSWORD")
+	}
+	pbURL := os.Getenv("PB_URL")
+	if pbURL == "" {
+		pbURL = "http://localhost:8090"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := pb.NewSuperuser(pb.NewClient(pbURL), email, password).Client(ctx)
+	if err != nil {
+		return fmt.Errorf("superuser sign-in failed: %w", err)
+	}
+	schemas, err := client.CollectionSchemas(ctx)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(schemas, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
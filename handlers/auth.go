@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"errors"
+	"log"
+
 	"github.com/torresposso/gosmic/middleware"
 	"github.com/torresposso/gosmic/pb"
 	"github.com/torresposso/gosmic/services"
@@ -8,26 +11,58 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/csrf"
+	"github.com/gofiber/fiber/v3/middleware/session"
+)
+
+// Session keys holding an OAuth2 sign-in in progress
+const (
+	oauth2ProviderKey = "oauth2_provider"
+	oauth2StateKey    = "oauth2_state"
+	oauth2VerifierKey = "oauth2_verifier"
+)
+
+// Session keys holding a password or OAuth2 sign-in waiting for its one-time code
+const (
+	mfaIDKey    = "mfa_id"
+	mfaOTPIDKey = "mfa_otp_id"
+	mfaEmailKey = "mfa_email"
 )
 
 type AuthHandler struct {
 	authService  services.AuthService
 	globalClient *pb.Client
+	sessStore    *session.Store
+	passkeys     services.PasskeyService // nil when passkey sign-in is disabled
+	users        *middleware.UserCache   // AuthMiddleware's record cache; nil when not shared
 }
 
-func NewAuthHandler(as services.AuthService, gc *pb.Client) *AuthHandler {
+func NewAuthHandler(as services.AuthService, gc *pb.Client, ss *session.Store, ps services.PasskeyService, users *middleware.UserCache) *AuthHandler {
 	return &AuthHandler{
 		authService:  as,
 		globalClient: gc,
+		sessStore:    ss,
+		passkeys:     ps,
+		users:        users,
 	}
 }
 
+// renderLogin renders the login page along with the available OAuth2 providers.
+func (h *AuthHandler) renderLogin(c fiber.Ctx, errorMsg, email string, registered bool) error {
+	providers, err := h.authService.OAuth2Providers(c.Context(), h.globalClient)
+	if err != nil {
+		// Password login still works without the provider list
+		log.Printf("failed to list oauth2 providers: %v", err)
+	}
+
+	csrfToken := csrf.TokenFromContext(c)
+	return RenderLayout(c, "Login", h.globalClient.WithToken(""), views.Login(errorMsg, email, registered, providers, h.passkeys != nil, csrfToken))
+}
+
 // ShowLogin renders the login form
 func (h *AuthHandler) ShowLogin() fiber.Handler {
 	return func(c fiber.Ctx) error {
 		registered := c.Query("registered") == "true"
-		csrfToken := csrf.TokenFromContext(c)
-		return RenderLayout(c, "Login", h.globalClient.WithToken(""), views.Login("", "", registered, csrfToken))
+		return h.renderLogin(c, "", "", registered)
 	}
 }
 
@@ -36,31 +71,342 @@ func (h *AuthHandler) Login() fiber.Handler {
 	return func(c fiber.Ctx) error {
 		email := c.FormValue("email")
 		password := c.FormValue("password")
-		csrfToken := csrf.TokenFromContext(c)
 
 		token, err := h.authService.Login(c.Context(), h.globalClient, email, password)
+		var mfaErr *pb.MFAError
+		if errors.As(err, &mfaErr) {
+			return h.startOTP(c, email, mfaErr.MFAID)
+		}
 		if err != nil {
-			return RenderLayout(c, "Login", h.globalClient.WithToken(""), views.Login(err.Error(), email, false, csrfToken))
+			message, _ := formError(err)
+			return h.renderLogin(c, message, email, false)
 		}
 
-		c.Cookie(&fiber.Cookie{
-			Name:     "pb_auth",
-			Value:    token,
-			HTTPOnly: true,
-			Secure:   c.Protocol() == "https", // Automatically detect
-			SameSite: "Lax",
-			Path:     "/",
-		})
+		middleware.SetAuthCookie(c, token)
 
 		return c.Redirect().To("/dashboard")
 	}
 }
 
+// startOTP emails a one-time code and parks the half-finished sign-in in the
+// session until the code is entered on /login/otp. Without an email, as after
+// an OAuth2 sign-in, /login/otp first asks for the account's address.
+func (h *AuthHandler) startOTP(c fiber.Ctx, email, mfaID string) error {
+	var otpID string
+	if email != "" {
+		var err error
+		otpID, err = h.authService.RequestOTP(c.Context(), h.globalClient, email)
+		if err != nil {
+			log.Printf("failed to request otp: %v", err)
+			return h.renderLogin(c, "Could not send a one-time code, please try again", email, false)
+		}
+	}
+
+	sess, err := h.sessStore.Get(c)
+	if err != nil {
+		return err
+	}
+	sess.Set(mfaIDKey, mfaID)
+	sess.Set(mfaOTPIDKey, otpID)
+	sess.Set(mfaEmailKey, email)
+	if err := sess.Save(); err != nil {
+		return err
+	}
+
+	return c.Redirect().To("/login/otp")
+}
+
+// ShowOTP renders the one-time code step of a multi-factor sign-in
+func (h *AuthHandler) ShowOTP() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		sess, err := h.sessStore.Get(c)
+		if err != nil {
+			return err
+		}
+		mfaID, _ := sess.Get(mfaIDKey).(string)
+		email, _ := sess.Get(mfaEmailKey).(string)
+		if mfaID == "" {
+			return c.Redirect().To("/login")
+		}
+
+		csrfToken := csrf.TokenFromContext(c)
+		return RenderLayout(c, "Second Clearance", h.globalClient.WithToken(""), views.LoginOTP("", email, csrfToken))
+	}
+}
+
+// VerifyOTP completes a multi-factor sign-in with the emailed code
+func (h *AuthHandler) VerifyOTP() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		sess, err := h.sessStore.Get(c)
+		if err != nil {
+			return err
+		}
+		mfaID, _ := sess.Get(mfaIDKey).(string)
+		otpID, _ := sess.Get(mfaOTPIDKey).(string)
+		email, _ := sess.Get(mfaEmailKey).(string)
+		if mfaID == "" {
+			return c.Redirect().To("/login")
+		}
+		if otpID == "" {
+			return c.Redirect().To("/login/otp")
+		}
+
+		token, err := h.authService.LoginWithOTP(c.Context(), h.globalClient, otpID, c.FormValue("code"), mfaID)
+		if err != nil {
+			message := "Invalid or expired code"
+			if !errors.Is(err, pb.ErrBadRequest) && !errors.Is(err, pb.ErrUnauthorized) {
+				message, _ = formError(err)
+			}
+			csrfToken := csrf.TokenFromContext(c)
+			return RenderLayout(c, "Second Clearance", h.globalClient.WithToken(""), views.LoginOTP(message, email, csrfToken))
+		}
+
+		sess.Delete(mfaIDKey)
+		sess.Delete(mfaOTPIDKey)
+		sess.Delete(mfaEmailKey)
+		if err := sess.Save(); err != nil {
+			return err
+		}
+
+		middleware.SetAuthCookie(c, token)
+
+		return c.Redirect().To("/dashboard")
+	}
+}
+
+// ResendOTP emails a fresh one-time code for the sign-in in progress, to the
+// address entered on /login/otp when the sign-in did not provide one
+func (h *AuthHandler) ResendOTP() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		sess, err := h.sessStore.Get(c)
+		if err != nil {
+			return err
+		}
+		mfaID, _ := sess.Get(mfaIDKey).(string)
+		email, _ := sess.Get(mfaEmailKey).(string)
+		if mfaID == "" {
+			return c.Redirect().To("/login")
+		}
+		if email == "" {
+			email = c.FormValue("email")
+		}
+
+		return h.startOTP(c, email, mfaID)
+	}
+}
+
+// OAuth2Redirect starts an OAuth2 sign-in: it remembers the provider's state and
+// PKCE verifier in the session and sends the browser to the provider.
+func (h *AuthHandler) OAuth2Redirect() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		providers, err := h.authService.OAuth2Providers(c.Context(), h.globalClient)
+		if err != nil {
+			log.Printf("failed to list oauth2 providers: %v", err)
+			return h.renderLogin(c, "Sign-in provider is unavailable, please try again", "", false)
+		}
+
+		name := c.Params("provider")
+		for _, provider := range providers {
+			if provider.Name != name {
+				continue
+			}
+
+			sess, err := h.sessStore.Get(c)
+			if err != nil {
+				return err
+			}
+			sess.Set(oauth2ProviderKey, provider.Name)
+			sess.Set(oauth2StateKey, provider.State)
+			sess.Set(oauth2VerifierKey, provider.CodeVerifier)
+			if err := sess.Save(); err != nil {
+				return err
+			}
+
+			return c.Redirect().To(provider.AuthURLWithRedirect(oauth2RedirectURL(c)))
+		}
+
+		return fiber.ErrNotFound
+	}
+}
+
+// OAuth2Callback finishes an OAuth2 sign-in once the provider redirects back,
+// checking the state before exchanging the code for a PocketBase token.
+func (h *AuthHandler) OAuth2Callback() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		sess, err := h.sessStore.Get(c)
+		if err != nil {
+			return err
+		}
+		provider, _ := sess.Get(oauth2ProviderKey).(string)
+		state, _ := sess.Get(oauth2StateKey).(string)
+		verifier, _ := sess.Get(oauth2VerifierKey).(string)
+
+		// The state and verifier are single-use
+		sess.Delete(oauth2ProviderKey)
+		sess.Delete(oauth2StateKey)
+		sess.Delete(oauth2VerifierKey)
+		if err := sess.Save(); err != nil {
+			return err
+		}
+
+		if c.Query("error") != "" {
+			return h.renderLogin(c, "Sign-in was cancelled", "", false)
+		}
+		if state == "" || c.Query("state") != state {
+			return h.renderLogin(c, "Sign-in session expired, please try again", "", false)
+		}
+
+		token, err := h.authService.LoginWithOAuth2(c.Context(), h.globalClient, provider, c.Query("code"), verifier, oauth2RedirectURL(c))
+		var mfaErr *pb.MFAError
+		if errors.As(err, &mfaErr) {
+			// PocketBase does not say which account signed in, so the code's
+			// address is asked for; it only completes this mfaId's sign-in
+			return h.startOTP(c, "", mfaErr.MFAID)
+		}
+		if err != nil {
+			message, _ := formError(err)
+			return h.renderLogin(c, message, "", false)
+		}
+
+		middleware.SetAuthCookie(c, token)
+
+		return c.Redirect().To("/dashboard")
+	}
+}
+
+// oauth2RedirectURL is where providers send the browser back to; it must be
+// identical when starting and when completing the sign-in.
+func oauth2RedirectURL(c fiber.Ctx) string {
+	return c.BaseURL() + "/auth/oauth2/callback"
+}
+
+// ShowForgotPassword renders the form to request a password reset link
+func (h *AuthHandler) ShowForgotPassword() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		csrfToken := csrf.TokenFromContext(c)
+		return RenderLayout(c, "Forgot Passcode", h.globalClient.WithToken(""), views.ForgotPassword("", "", csrfToken))
+	}
+}
+
+// ForgotPassword sends a reset link. The confirmation is the same whether or not
+// the email belongs to an account, so the form cannot be used to discover users.
+func (h *AuthHandler) ForgotPassword() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		email := c.FormValue("email")
+		csrfToken := csrf.TokenFromContext(c)
+
+		if err := h.authService.RequestPasswordReset(c.Context(), h.globalClient, email); err != nil {
+			message := "Could not send the reset link, please try again"
+			if errors.Is(err, pb.ErrBadRequest) || email == "" {
+				message, _ = formError(err)
+			} else {
+				log.Printf("password reset request failed: %v", err)
+			}
+			return RenderLayout(c, "Forgot Passcode", h.globalClient.WithToken(""), views.ForgotPassword(message, email, csrfToken))
+		}
+
+		h.setFlash(c, "If that comms ID is enlisted, a reset link is on its way", "success")
+		return c.Redirect().To("/login")
+	}
+}
+
+// ShowResetPassword renders the form to choose a new password.
+// PocketBase's reset email template should link to /reset-password/{TOKEN}.
+func (h *AuthHandler) ShowResetPassword() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		csrfToken := csrf.TokenFromContext(c)
+		return RenderLayout(c, "Reset Passcode", h.globalClient.WithToken(""), views.ResetPassword(c.Params("token"), "", nil, csrfToken))
+	}
+}
+
+// ResetPassword sets the new password with the token from the reset email
+func (h *AuthHandler) ResetPassword() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		token := c.Params("token")
+		password := c.FormValue("password")
+		passwordConfirm := c.FormValue("passwordConfirm")
+		csrfToken := csrf.TokenFromContext(c)
+
+		err := h.authService.ResetPassword(c.Context(), h.globalClient, token, password, passwordConfirm)
+		if err != nil {
+			message, fieldErrors := formError(err)
+			if _, ok := fieldErrors["token"]; ok {
+				message = "This reset link is invalid or has expired"
+			}
+			return RenderLayout(c, "Reset Passcode", h.globalClient.WithToken(""), views.ResetPassword(token, message, fieldErrors, csrfToken))
+		}
+
+		h.setFlash(c, "Passcode updated. Verify your identity with the new passcode", "success")
+		return c.Redirect().To("/login")
+	}
+}
+
+// VerifyEmail confirms an account from the link in the verification email.
+// PocketBase's verification email template should link to /verify/{TOKEN}.
+func (h *AuthHandler) VerifyEmail() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		next := "/login"
+		if c.Cookies(middleware.AuthCookie) != "" {
+			next = "/dashboard"
+		}
+
+		token := c.Params("token")
+		if err := h.authService.VerifyEmail(c.Context(), h.globalClient, token); err != nil {
+			h.setFlash(c, "This verification link is invalid or has expired", "error")
+			return c.Redirect().To(next)
+		}
+
+		// Cached records still say unverified; the link may be opened in another browser,
+		// so the user comes from the verification token as well as the session
+		if h.users != nil {
+			if claims, err := pb.ParseToken(token); err == nil && claims.ID != "" {
+				h.users.Forget(claims.ID)
+			}
+			if claims, err := pb.ParseToken(c.Cookies(middleware.AuthCookie)); err == nil && claims.ID != "" {
+				h.users.Forget(claims.ID)
+			}
+		}
+
+		h.setFlash(c, "Comms ID confirmed. Public broadcasts unlocked", "success")
+		return c.Redirect().To(next)
+	}
+}
+
+// ResendVerification emails the signed-in user a new verification link
+func (h *AuthHandler) ResendVerification() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		client := middleware.GetPBClient(c)
+		if client == nil {
+			return c.Redirect().To("/login")
+		}
+
+		if err := h.authService.ResendVerification(c.Context(), client, client.GetCurrentUserEmail()); err != nil {
+			log.Printf("failed to resend verification email: %v", err)
+			h.setFlash(c, "Could not send the verification link, please try again", "error")
+			return c.Redirect().To("/dashboard")
+		}
+
+		h.setFlash(c, "Verification link sent to "+client.GetCurrentUserEmail(), "success")
+		return c.Redirect().To("/dashboard")
+	}
+}
+
+// setFlash queues a message for the next page the user sees
+func (h *AuthHandler) setFlash(c fiber.Ctx, message, flashType string) {
+	sess, err := h.sessStore.Get(c)
+	if err != nil {
+		return
+	}
+	sess.Set("flash", message)
+	sess.Set("flash_type", flashType)
+	sess.Save()
+}
+
 // ShowRegister renders the registration form
 func (h *AuthHandler) ShowRegister() fiber.Handler {
 	return func(c fiber.Ctx) error {
 		csrfToken := csrf.TokenFromContext(c)
-		return RenderLayout(c, "Register", h.globalClient.WithToken(""), views.Register("", "", "", csrfToken))
+		return RenderLayout(c, "Register", h.globalClient.WithToken(""), views.Register("", "", "", nil, csrfToken))
 	}
 }
 
@@ -74,12 +420,13 @@ func (h *AuthHandler) Register() fiber.Handler {
 		csrfToken := csrf.TokenFromContext(c)
 
 		if password != passwordConfirm {
-			return RenderLayout(c, "Register", h.globalClient.WithToken(""), views.Register("Passwords do not match", email, name, csrfToken))
+			return RenderLayout(c, "Register", h.globalClient.WithToken(""), views.Register("Passwords do not match", email, name, map[string]string{"passwordConfirm": "Passwords do not match"}, csrfToken))
 		}
 
 		err := h.authService.Register(c.Context(), h.globalClient, email, password, name)
 		if err != nil {
-			return RenderLayout(c, "Register", h.globalClient.WithToken(""), views.Register(err.Error(), email, name, csrfToken))
+			message, fieldErrors := formError(err)
+			return RenderLayout(c, "Register", h.globalClient.WithToken(""), views.Register(message, email, name, fieldErrors, csrfToken))
 		}
 
 		return c.Redirect().To("/login?registered=true")
@@ -89,7 +436,7 @@ func (h *AuthHandler) Register() fiber.Handler {
 // Logout clears the authentication cookie
 func (h *AuthHandler) Logout() fiber.Handler {
 	return func(c fiber.Ctx) error {
-		c.ClearCookie("pb_auth")
+		middleware.ClearAuthCookie(c)
 		return c.Redirect().To("/")
 	}
 }
@@ -121,13 +468,20 @@ func (h *RootHandler) Dashboard() fiber.Handler {
 			return c.Redirect().To("/login")
 		}
 
-		posts, err := h.postService.List(c.Context(), client, "")
+		// One post is enough to learn the total
+		result, err := h.postService.List(c.Context(), client, services.PostQuery{PerPage: 1})
+		if errors.Is(err, pb.ErrUnavailable) {
+			return renderUnavailable(c, client)
+		}
+		count := 0
 		if err != nil {
-			posts = []pb.Post{}
+			log.Printf("failed to count posts: %v", err)
+		} else {
+			count = result.TotalItems
 		}
 
 		csrfToken := csrf.TokenFromContext(c)
 		return RenderLayout(c, "Dashboard", client,
-			views.Dashboard(client.GetCurrentUserName(), client.GetCurrentUserEmail(), len(posts), csrfToken))
+			views.Dashboard(client.GetCurrentUserName(), client.GetCurrentUserEmail(), client.GetCurrentUserAvatarURL(), client.IsVerified(), count, csrfToken))
 	}
 }
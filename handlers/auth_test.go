@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/session"
 	"github.com/stretchr/testify/assert"
 	"github.com/torresposso/gosmic/middleware"
 	"github.com/torresposso/gosmic/pb"
@@ -57,7 +58,7 @@ func TestLoginHandler(t *testing.T) {
 	app := fiber.New()
 	authRepo := repositories.NewAuthRepository()
 	authService := services.NewAuthService(authRepo)
-	authHandler := NewAuthHandler(authService, pbClient)
+	authHandler := NewAuthHandler(authService, pbClient, session.NewStore(), nil, nil)
 	app.Post("/login", authHandler.Login())
 
 	// Test Case: Successful Login
@@ -113,7 +114,7 @@ func TestLogoutHandler(t *testing.T) {
 	pbClient := pb.NewClient("http://mock-pb")
 	authRepo := repositories.NewAuthRepository()
 	authService := services.NewAuthService(authRepo)
-	authHandler := NewAuthHandler(authService, pbClient)
+	authHandler := NewAuthHandler(authService, pbClient, session.NewStore(), nil, nil)
 	app.Get("/logout", authHandler.Logout())
 
 	req := httptest.NewRequest("GET", "/logout", nil)
@@ -132,3 +133,42 @@ func TestLogoutHandler(t *testing.T) {
 		}
 	}
 }
+
+func TestRegisterHandler_FieldErrors(t *testing.T) {
+	mockTripper := &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) *http.Response {
+			body := `{"status": 400, "message": "Failed to create record.", "data": {"email": {"code": "validation_not_unique", "message": "Value must be unique."}}}`
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Header:     make(http.Header),
+			}
+		},
+	}
+
+	pbClient := pb.NewClient("http://mock-pb")
+	pbClient.HTTPClient.Transport = mockTripper
+
+	app := fiber.New()
+	authHandler := NewAuthHandler(services.NewAuthService(repositories.NewAuthRepository()), pbClient, session.NewStore(), nil, nil)
+	app.Post("/register", authHandler.Register())
+
+	form := url.Values{}
+	form.Add("email", "taken@example.com")
+	form.Add("password", "password123")
+	form.Add("passwordConfirm", "password123")
+	form.Add("name", "Taken User")
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	body := string(bodyBytes)
+	assert.Contains(t, body, "Please correct the highlighted fields")
+	assert.Contains(t, body, "Value must be unique.")
+	assert.Contains(t, body, "input-error")
+	assert.NotContains(t, body, "validation_not_unique")
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/gofiber/fiber/v3/middleware/csrf"
 	"github.com/stretchr/testify/assert"
 	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/repositories"
+	"github.com/torresposso/gosmic/services"
 )
 
 func TestDashboardAccess(t *testing.T) {
@@ -55,15 +57,16 @@ func TestDashboardAccess(t *testing.T) {
 		// Mock authenticated user
 		userClient := pbClient.WithToken("mock-token")
 		userClient.AuthRecord = &pb.User{
-			ID:    "user123",
-			Email: "test@example.com",
-			Name:  "Test User",
+			ID:     "user123",
+			Email:  "test@example.com",
+			Name:   "Test User",
+			Avatar: "portrait_k3j4h5.png",
 		}
 		c.Locals("pb", userClient)
 		return c.Next()
 	})
 
-	app.Get("/dashboard", Dashboard())
+	app.Get("/dashboard", NewRootHandler(pbClient, services.NewPostService(repositories.NewPostRepository(), services.VerificationPolicy{})).Dashboard())
 
 	req := httptest.NewRequest("GET", "/dashboard", nil)
 	resp, err := app.Test(req)
@@ -76,5 +79,37 @@ func TestDashboardAccess(t *testing.T) {
 	assert.Contains(t, bodyStr, "Welcome aboard")
 	assert.Contains(t, bodyStr, "Commander Test User")
 	assert.Contains(t, bodyStr, "test@example.com")
+	assert.Contains(t, bodyStr, `src="http://mock-pb/api/files/users/user123/portrait_k3j4h5.png"`)
 	// assert.Contains(t, bodyStr, "1") // Post count check, brittle if format changes
 }
+
+func TestDashboardPocketBaseUnreachable(t *testing.T) {
+	pocketbase := httptest.NewServer(http.NotFoundHandler())
+	pocketbase.Close()
+	pbClient := pb.NewClient(pocketbase.URL, pb.WithRetryPolicy(pb.RetryPolicy{MaxAttempts: 1}))
+
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		userClient := pbClient.WithToken("mock-token")
+		userClient.AuthRecord = &pb.User{ID: "user123", Email: "test@example.com"}
+		c.Locals("pb", userClient)
+		return c.Next()
+	})
+	app.Get("/dashboard", NewRootHandler(pbClient, services.NewPostService(repositories.NewPostRepository(), services.VerificationPolicy{})).Dashboard())
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/dashboard", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "30", resp.Header.Get("Retry-After"))
+
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(bodyBytes), "Mission Control Unreachable")
+	assert.NotContains(t, string(bodyBytes), "Welcome aboard")
+
+	// Boosted navigations still get the page swapped in
+	req := httptest.NewRequest("GET", "/dashboard", nil)
+	req.Header.Set("HX-Request", "true")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
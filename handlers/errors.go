@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/views"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// unavailableMessage replaces any error caused by PocketBase being unreachable
+const unavailableMessage = "Mission control is unreachable, please try again in a moment"
+
+// formError splits err into a summary for the form and PocketBase's per-field validation messages.
+// Errors that did not come from PocketBase are shown as-is with no field details.
+func formError(err error) (string, map[string]string) {
+	if errors.Is(err, pb.ErrUnavailable) {
+		return unavailableMessage, nil
+	}
+
+	var apiErr *pb.APIError
+	if !errors.As(err, &apiErr) {
+		return err.Error(), nil
+	}
+
+	if fields := apiErr.FieldErrors(); len(fields) > 0 {
+		return "Please correct the highlighted fields", fields
+	}
+	return apiErr.Message, nil
+}
+
+// renderUnavailable renders the "PocketBase unreachable" page. Boosted htmx
+// navigations get a 200 so htmx swaps the page in instead of dropping it.
+func renderUnavailable(c fiber.Ctx, client *pb.Client) error {
+	if c.Get("HX-Request") != "true" {
+		c.Status(fiber.StatusServiceUnavailable)
+	}
+	c.Set(fiber.HeaderRetryAfter, "30")
+	return RenderLayout(c, "Mission Control Unreachable", client, views.Unavailable())
+}
@@ -1,12 +1,10 @@
 package handlers
 
 import (
-	"github.com/torresposso/gosmic/middleware"
 	"github.com/torresposso/gosmic/pb"
 	"github.com/torresposso/gosmic/views"
 
 	"github.com/gofiber/fiber/v3"
-	"github.com/gofiber/fiber/v3/middleware/csrf"
 )
 
 // Home renders the landing page (public route)
@@ -18,23 +16,3 @@ func Home(globalClient *pb.Client) fiber.Handler {
 		return RenderLayout(c, "Fiber v3 + PocketBase Tutorial", userClient, views.Index(userClient.IsAuthenticated()))
 	}
 }
-
-// Dashboard renders the user's dashboard (protected route)
-func Dashboard() fiber.Handler {
-	return func(c fiber.Ctx) error {
-		client := middleware.GetPBClient(c)
-		if client == nil {
-			return c.Redirect().To("/login")
-		}
-
-		posts, err := client.ListPosts()
-		if err != nil {
-			// Return empty list instead of failing page load? Or handle error gracefully
-			posts = []pb.Post{}
-		}
-
-		csrfToken := csrf.TokenFromContext(c)
-		return RenderLayout(c, "Dashboard", client,
-			views.Dashboard(client.GetCurrentUserName(), client.GetCurrentUserEmail(), len(posts), csrfToken))
-	}
-}
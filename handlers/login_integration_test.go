@@ -15,6 +15,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/extractors"
 	"github.com/gofiber/fiber/v3/middleware/csrf"
+	"github.com/gofiber/fiber/v3/middleware/session"
 	"github.com/stretchr/testify/assert"
 	"github.com/torresposso/gosmic/pb"
 	"github.com/torresposso/gosmic/repositories"
@@ -62,7 +63,7 @@ func TestLoginFlowWithCSRF(t *testing.T) {
 	// Initialize Services and Handlers for testing
 	authRepo := repositories.NewAuthRepository()
 	authService := services.NewAuthService(authRepo)
-	authHandler := NewAuthHandler(authService, pbClient)
+	authHandler := NewAuthHandler(authService, pbClient, session.NewStore(), nil, nil)
 
 	app.Get("/login", authHandler.ShowLogin())
 	app.Post("/login", authHandler.Login())
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/repositories"
+	"github.com/torresposso/gosmic/services"
+)
+
+func TestMFALoginFlow(t *testing.T) {
+	otpRequests := 0
+	pocketbase := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/api/collections/users/auth-with-password":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status": 401, "message": "Please complete the MFA authentication.", "data": {"mfaId": "mfa-1"}}`))
+		case "/api/collections/users/request-otp":
+			otpRequests++
+			assert.Equal(t, "test@example.com", body["email"])
+			json.NewEncoder(w).Encode(map[string]string{"otpId": fmt.Sprintf("otp-%d", otpRequests)})
+		case "/api/collections/users/auth-with-otp":
+			assert.Equal(t, "mfa-1", body["mfaId"])
+			if body["password"] != "123456" || body["otpId"] != fmt.Sprintf("otp-%d", otpRequests) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"status": 400, "message": "Failed to authenticate.", "data": {}}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"token":  "mfa-pb-token",
+				"record": map[string]any{"id": "user123", "email": "test@example.com"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer pocketbase.Close()
+
+	app := fiber.New()
+	authHandler := NewAuthHandler(services.NewAuthService(repositories.NewAuthRepository()), pb.NewClient(pocketbase.URL), session.NewStore(), nil, nil)
+	app.Post("/login", authHandler.Login())
+	app.Get("/login/otp", authHandler.ShowOTP())
+	app.Post("/login/otp", authHandler.VerifyOTP())
+	app.Post("/login/otp/resend", authHandler.ResendOTP())
+
+	send := func(method, path string, form url.Values, cookies []*http.Cookie) *http.Response {
+		req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	authCookie := func(resp *http.Response) *http.Cookie {
+		for _, c := range resp.Cookies() {
+			if c.Name == "pb_auth" {
+				return c
+			}
+		}
+		return nil
+	}
+
+	t.Run("OTPStepRequiresPasswordFirst", func(t *testing.T) {
+		resp := send("GET", "/login/otp", nil, nil)
+		assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+		assert.Equal(t, "/login", resp.Header.Get("Location"))
+
+		resp = send("POST", "/login/otp", url.Values{"code": {"123456"}}, nil)
+		assert.Equal(t, "/login", resp.Header.Get("Location"))
+		assert.Nil(t, authCookie(resp))
+	})
+
+	t.Run("PasswordThenCode", func(t *testing.T) {
+		otpRequests = 0
+		resp := send("POST", "/login", url.Values{"email": {"test@example.com"}, "password": {"password123"}}, nil)
+
+		assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+		assert.Equal(t, "/login/otp", resp.Header.Get("Location"))
+		assert.Nil(t, authCookie(resp), "no session before the second factor")
+		assert.Equal(t, 1, otpRequests)
+		session := resp.Cookies()
+
+		page := send("GET", "/login/otp", nil, session)
+		body, _ := io.ReadAll(page.Body)
+		assert.Contains(t, string(body), "test@example.com")
+		assert.Contains(t, string(body), `autocomplete="one-time-code"`)
+
+		wrong := send("POST", "/login/otp", url.Values{"code": {"000000"}}, session)
+		assert.Equal(t, http.StatusOK, wrong.StatusCode)
+		body, _ = io.ReadAll(wrong.Body)
+		assert.Contains(t, string(body), "Invalid or expired code")
+		assert.Nil(t, authCookie(wrong))
+
+		// A resent code replaces the previous otpId
+		resent := send("POST", "/login/otp/resend", nil, session)
+		assert.Equal(t, "/login/otp", resent.Header.Get("Location"))
+		assert.Equal(t, 2, otpRequests)
+
+		done := send("POST", "/login/otp", url.Values{"code": {"123456"}}, session)
+		assert.Equal(t, http.StatusSeeOther, done.StatusCode)
+		assert.Equal(t, "/dashboard", done.Header.Get("Location"))
+		require.NotNil(t, authCookie(done))
+		assert.Equal(t, "mfa-pb-token", authCookie(done).Value)
+
+		// The partial sign-in is cleared once used
+		again := send("GET", "/login/otp", nil, session)
+		assert.Equal(t, "/login", again.Header.Get("Location"))
+	})
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/repositories"
+	"github.com/torresposso/gosmic/services"
+)
+
+func TestOAuth2Flow(t *testing.T) {
+	// Fake OAuth provider: approves every request and sends the browser back with a code
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "/authorize", r.URL.Path)
+		assert.Equal(t, "challenge-1", query.Get("code_challenge"))
+
+		callback, err := url.Parse(query.Get("redirect_uri"))
+		require.NoError(t, err)
+		callback.RawQuery = url.Values{"code": {"provider-code"}, "state": {query.Get("state")}}.Encode()
+		http.Redirect(w, r, callback.String(), http.StatusFound)
+	}))
+	defer provider.Close()
+
+	exchanges := 0
+	requireMFA := false
+	otpRequests := 0
+	pocketbase := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/collections/users/auth-methods":
+			json.NewEncoder(w).Encode(map[string]any{
+				"password": map[string]any{"enabled": true, "identityFields": []string{"email"}},
+				"oauth2": map[string]any{
+					"enabled": true,
+					"providers": []map[string]any{{
+						"name":                "fake",
+						"displayName":         "Fake Fleet ID",
+						"state":               "state-1",
+						"codeVerifier":        "verifier-1",
+						"codeChallenge":       "challenge-1",
+						"codeChallengeMethod": "S256",
+						"authURL":             provider.URL + "/authorize?client_id=gosmic&state=state-1&code_challenge=challenge-1&code_challenge_method=S256&redirect_uri=",
+					}},
+				},
+			})
+		case "/api/collections/users/auth-with-oauth2":
+			exchanges++
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, "fake", body["provider"])
+			assert.Equal(t, "provider-code", body["code"])
+			assert.Equal(t, "verifier-1", body["codeVerifier"])
+			assert.Equal(t, "http://example.com/auth/oauth2/callback", body["redirectURL"])
+
+			if requireMFA {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"status": 401, "message": "Please complete the MFA authentication.", "data": {"mfaId": "mfa-oauth2"}}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"token":  "oauth2-pb-token",
+				"record": map[string]any{"id": "user123", "email": "test@example.com"},
+			})
+		case "/api/collections/users/request-otp":
+			otpRequests++
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, "test@example.com", body["email"])
+			json.NewEncoder(w).Encode(map[string]string{"otpId": "otp-1"})
+		case "/api/collections/users/auth-with-otp":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, "mfa-oauth2", body["mfaId"])
+			assert.Equal(t, "otp-1", body["otpId"])
+			json.NewEncoder(w).Encode(map[string]any{
+				"token":  "mfa-pb-token",
+				"record": map[string]any{"id": "user123", "email": "test@example.com"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer pocketbase.Close()
+
+	app := fiber.New()
+	authHandler := NewAuthHandler(services.NewAuthService(repositories.NewAuthRepository()), pb.NewClient(pocketbase.URL), session.NewStore(), nil, nil)
+	app.Get("/login", authHandler.ShowLogin())
+	app.Get("/auth/oauth2/callback", authHandler.OAuth2Callback())
+	app.Get("/auth/oauth2/:provider", authHandler.OAuth2Redirect())
+	app.Get("/login/otp", authHandler.ShowOTP())
+	app.Post("/login/otp", authHandler.VerifyOTP())
+	app.Post("/login/otp/resend", authHandler.ResendOTP())
+
+	// Browser that does not follow redirects, so each hop can be inspected
+	browser := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	// start begins a sign-in and returns the session cookie and the provider's callback URL
+	start := func(t *testing.T) (*http.Cookie, *url.URL) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/auth/oauth2/fake", nil))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+
+		var sessionCookie *http.Cookie
+		for _, c := range resp.Cookies() {
+			if c.Name == "session_id" {
+				sessionCookie = c
+			}
+		}
+		require.NotNil(t, sessionCookie)
+
+		providerResp, err := browser.Get(resp.Header.Get("Location"))
+		require.NoError(t, err)
+		providerResp.Body.Close()
+		callback, err := url.Parse(providerResp.Header.Get("Location"))
+		require.NoError(t, err)
+		return sessionCookie, callback
+	}
+
+	callback := func(sessionCookie *http.Cookie, query string) *http.Response {
+		req := httptest.NewRequest("GET", "/auth/oauth2/callback?"+query, nil)
+		req.AddCookie(sessionCookie)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("LoginListsProviders", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/login", nil))
+		require.NoError(t, err)
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), `href="/auth/oauth2/fake"`)
+		assert.Contains(t, string(body), "Fake Fleet ID")
+	})
+
+	t.Run("UnknownProvider", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/auth/oauth2/unknown", nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		exchanges = 0
+		sessionCookie, callbackURL := start(t)
+		assert.Equal(t, "/auth/oauth2/callback", callbackURL.Path)
+
+		resp := callback(sessionCookie, callbackURL.RawQuery)
+
+		assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+		assert.Equal(t, "/dashboard", resp.Header.Get("Location"))
+		assert.Equal(t, 1, exchanges)
+
+		var authCookie *http.Cookie
+		for _, c := range resp.Cookies() {
+			if c.Name == "pb_auth" {
+				authCookie = c
+			}
+		}
+		require.NotNil(t, authCookie)
+		assert.Equal(t, "oauth2-pb-token", authCookie.Value)
+		assert.True(t, authCookie.HttpOnly)
+
+		// The state is single-use, so replaying the callback fails
+		replay := callback(sessionCookie, callbackURL.RawQuery)
+		assert.Equal(t, http.StatusOK, replay.StatusCode)
+		assert.Equal(t, 1, exchanges)
+	})
+
+	t.Run("MFA", func(t *testing.T) {
+		requireMFA, otpRequests = true, 0
+		defer func() { requireMFA = false }()
+		sessionCookie, callbackURL := start(t)
+
+		resp := callback(sessionCookie, callbackURL.RawQuery)
+		assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+		assert.Equal(t, "/login/otp", resp.Header.Get("Location"))
+		for _, c := range resp.Cookies() {
+			assert.NotEqual(t, "pb_auth", c.Name, "no session before the second factor")
+		}
+
+		send := func(method, path string, form url.Values) *http.Response {
+			req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(sessionCookie)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			return resp
+		}
+
+		// PocketBase does not name the account, so the code's address is asked for
+		page := send("GET", "/login/otp", nil)
+		body, _ := io.ReadAll(page.Body)
+		assert.Contains(t, string(body), `name="email"`)
+		assert.NotContains(t, string(body), `autocomplete="one-time-code"`)
+		early := send("POST", "/login/otp", url.Values{"code": {"123456"}})
+		assert.Equal(t, "/login/otp", early.Header.Get("Location"))
+		assert.Equal(t, 0, otpRequests)
+
+		sent := send("POST", "/login/otp/resend", url.Values{"email": {"test@example.com"}})
+		assert.Equal(t, "/login/otp", sent.Header.Get("Location"))
+		assert.Equal(t, 1, otpRequests)
+		page = send("GET", "/login/otp", nil)
+		body, _ = io.ReadAll(page.Body)
+		assert.Contains(t, string(body), "test@example.com")
+		assert.Contains(t, string(body), `autocomplete="one-time-code"`)
+
+		done := send("POST", "/login/otp", url.Values{"code": {"123456"}})
+		assert.Equal(t, "/dashboard", done.Header.Get("Location"))
+		var authCookie *http.Cookie
+		for _, c := range done.Cookies() {
+			if c.Name == "pb_auth" {
+				authCookie = c
+			}
+		}
+		require.NotNil(t, authCookie)
+		assert.Equal(t, "mfa-pb-token", authCookie.Value)
+	})
+
+	t.Run("StateMismatch", func(t *testing.T) {
+		exchanges = 0
+		sessionCookie, _ := start(t)
+
+		resp := callback(sessionCookie, "code=provider-code&state=forged")
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), "Sign-in session expired")
+		assert.Equal(t, 0, exchanges)
+		for _, c := range resp.Cookies() {
+			assert.NotEqual(t, "pb_auth", c.Name)
+		}
+	})
+
+	t.Run("ProviderDenied", func(t *testing.T) {
+		sessionCookie, _ := start(t)
+
+		resp := callback(sessionCookie, "error=access_denied&state=state-1")
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), "Sign-in was cancelled")
+	})
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/torresposso/gosmic/middleware"
+	"github.com/torresposso/gosmic/services"
+	"github.com/torresposso/gosmic/views"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/csrf"
+)
+
+// Session keys holding a WebAuthn ceremony in progress
+const (
+	passkeyLoginKey    = "passkey_login"
+	passkeyRegisterKey = "passkey_register"
+)
+
+// The passkey endpoints are called from static/js/passkeys.js and answer in JSON.
+// Requests are form-encoded so the CSRF token travels in the _csrf field, with the
+// browser's credential serialized as JSON in the credential field.
+
+// ShowPasskeys lists the signed-in user's passkeys with a button to add one
+func (h *AuthHandler) ShowPasskeys() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		client := middleware.GetPBClient(c)
+		if client == nil {
+			return c.Redirect().To("/login")
+		}
+
+		csrfToken := csrf.TokenFromContext(c)
+		if h.passkeys == nil {
+			return RenderLayout(c, "Passkeys", client, views.Passkeys(false, nil, csrfToken))
+		}
+
+		credentials, err := h.passkeys.Passkeys(c.Context(), client)
+		if err != nil {
+			log.Printf("failed to list passkeys: %v", err)
+		}
+		return RenderLayout(c, "Passkeys", client, views.Passkeys(true, credentials, csrfToken))
+	}
+}
+
+// BeginPasskeyRegistration returns the options for navigator.credentials.create
+func (h *AuthHandler) BeginPasskeyRegistration() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if h.passkeys == nil {
+			return fiber.ErrNotFound
+		}
+		client := middleware.GetPBClient(c)
+		if client == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		options, state, err := h.passkeys.BeginRegistration(c.Context(), client)
+		if err != nil {
+			log.Printf("failed to begin passkey registration: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not start passkey registration"})
+		}
+
+		if err := h.saveCeremony(c, passkeyRegisterKey, state); err != nil {
+			return err
+		}
+		return c.JSON(options)
+	}
+}
+
+// FinishPasskeyRegistration verifies the new credential and stores it
+func (h *AuthHandler) FinishPasskeyRegistration() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if h.passkeys == nil {
+			return fiber.ErrNotFound
+		}
+		client := middleware.GetPBClient(c)
+		if client == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		state, err := h.takeCeremony(c, passkeyRegisterKey)
+		if err != nil {
+			return err
+		}
+		if state == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Registration expired, please try again"})
+		}
+
+		err = h.passkeys.FinishRegistration(c.Context(), client, state, c.FormValue("credential"), c.FormValue("name"))
+		if errors.Is(err, services.ErrPasskeyRejected) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Passkey could not be verified"})
+		}
+		if err != nil {
+			log.Printf("failed to register passkey: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not save the passkey"})
+		}
+
+		h.setFlash(c, "Passkey added. Use it next time you identify", "success")
+		return c.JSON(fiber.Map{"redirect": "/dashboard/passkeys"})
+	}
+}
+
+// BeginPasskeyLogin returns the options for navigator.credentials.get.
+// No email is asked for: the passkey itself names its owner.
+func (h *AuthHandler) BeginPasskeyLogin() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if h.passkeys == nil {
+			return fiber.ErrNotFound
+		}
+
+		options, state, err := h.passkeys.BeginLogin(c.Context())
+		if err != nil {
+			log.Printf("failed to begin passkey login: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not start passkey sign-in"})
+		}
+
+		if err := h.saveCeremony(c, passkeyLoginKey, state); err != nil {
+			return err
+		}
+		return c.JSON(options)
+	}
+}
+
+// FinishPasskeyLogin verifies the assertion and sets the PocketBase token in a cookie
+func (h *AuthHandler) FinishPasskeyLogin() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if h.passkeys == nil {
+			return fiber.ErrNotFound
+		}
+
+		state, err := h.takeCeremony(c, passkeyLoginKey)
+		if err != nil {
+			return err
+		}
+		if state == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Sign-in session expired, please try again"})
+		}
+
+		token, err := h.passkeys.FinishLogin(c.Context(), state, c.FormValue("credential"))
+		if errors.Is(err, services.ErrPasskeyRejected) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Passkey not recognized"})
+		}
+		if err != nil {
+			log.Printf("failed to finish passkey login: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not sign in with the passkey"})
+		}
+
+		middleware.SetAuthCookie(c, token)
+
+		return c.JSON(fiber.Map{"redirect": "/dashboard"})
+	}
+}
+
+// saveCeremony keeps a ceremony's state in the session until it is finished
+func (h *AuthHandler) saveCeremony(c fiber.Ctx, key, state string) error {
+	sess, err := h.sessStore.Get(c)
+	if err != nil {
+		return err
+	}
+	sess.Set(key, state)
+	return sess.Save()
+}
+
+// takeCeremony returns a ceremony's state and removes it; each challenge is single-use
+func (h *AuthHandler) takeCeremony(c fiber.Ctx, key string) (string, error) {
+	sess, err := h.sessStore.Get(c)
+	if err != nil {
+		return "", err
+	}
+	state, _ := sess.Get(key).(string)
+	sess.Delete(key)
+	if err := sess.Save(); err != nil {
+		return "", err
+	}
+	return state, nil
+}
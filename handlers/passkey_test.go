@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/repositories"
+	"github.com/torresposso/gosmic/services"
+)
+
+func TestPasskeyLogin(t *testing.T) {
+	passkeys := new(services.MockPasskeyService)
+	app := fiber.New()
+	authHandler := NewAuthHandler(services.NewAuthService(repositories.NewAuthRepository()), pb.NewClient("http://mock-pb"), session.NewStore(), passkeys, nil)
+	app.Post("/login/passkey/begin", authHandler.BeginPasskeyLogin())
+	app.Post("/login/passkey/finish", authHandler.FinishPasskeyLogin())
+
+	send := func(path string, form url.Values, cookies []*http.Cookie) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		options := &protocol.CredentialAssertion{Response: protocol.PublicKeyCredentialRequestOptions{RelyingPartyID: "gosmic.test"}}
+		passkeys.On("BeginLogin", mock.Anything).Return(options, "state-1", nil).Once()
+		passkeys.On("FinishLogin", mock.Anything, "state-1", `{"id":"cred"}`).Return("passkey-token", nil).Once()
+
+		resp := send("/login/passkey/begin", nil, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "gosmic.test", body["publicKey"].(map[string]any)["rpId"])
+
+		resp = send("/login/passkey/finish", url.Values{"credential": {`{"id":"cred"}`}}, resp.Cookies())
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "/dashboard", body["redirect"])
+
+		var authCookie *http.Cookie
+		for _, c := range resp.Cookies() {
+			if c.Name == "pb_auth" {
+				authCookie = c
+			}
+		}
+		require.NotNil(t, authCookie)
+		assert.Equal(t, "passkey-token", authCookie.Value)
+	})
+
+	t.Run("ChallengeIsSingleUse", func(t *testing.T) {
+		passkeys.On("BeginLogin", mock.Anything).Return(&protocol.CredentialAssertion{}, "state-2", nil).Once()
+		passkeys.On("FinishLogin", mock.Anything, "state-2", "{}").Return("", services.ErrPasskeyRejected).Once()
+
+		cookies := send("/login/passkey/begin", nil, nil).Cookies()
+
+		resp := send("/login/passkey/finish", url.Values{"credential": {"{}"}}, cookies)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = send("/login/passkey/finish", url.Values{"credential": {"{}"}}, cookies)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		for _, c := range resp.Cookies() {
+			assert.NotEqual(t, "pb_auth", c.Name)
+		}
+	})
+
+	passkeys.AssertExpectations(t)
+}
+
+func TestPasskeyLoginDisabled(t *testing.T) {
+	app := fiber.New()
+	authHandler := NewAuthHandler(services.NewAuthService(repositories.NewAuthRepository()), pb.NewClient("http://mock-pb"), session.NewStore(), nil, nil)
+	app.Post("/login/passkey/begin", authHandler.BeginPasskeyLogin())
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/login/passkey/begin", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
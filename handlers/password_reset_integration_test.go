@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/middleware"
+	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/repositories"
+	"github.com/torresposso/gosmic/services"
+)
+
+func TestPasswordResetFlow(t *testing.T) {
+	var resetRequests []string
+	pocketbase := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/api/collections/users/request-password-reset":
+			resetRequests = append(resetRequests, body["email"])
+			if body["email"] == "ghost@example.com" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"status": 404, "message": "The requested resource wasn't found.", "data": {}}`))
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case "/api/collections/users/confirm-password-reset":
+			if body["token"] != "good-token" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"status": 400, "message": "Failed to authenticate.", "data": {"token": {"code": "validation_invalid_token", "message": "Invalid or expired token."}}}`))
+				return
+			}
+			assert.Equal(t, "new-password", body["password"])
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer pocketbase.Close()
+
+	store := session.NewStore()
+	app := fiber.New()
+	app.Use(middleware.FlashMiddleware(store))
+	authHandler := NewAuthHandler(services.NewAuthService(repositories.NewAuthRepository()), pb.NewClient(pocketbase.URL), store, nil, nil)
+	app.Get("/login", authHandler.ShowLogin())
+	app.Get("/forgot-password", authHandler.ShowForgotPassword())
+	app.Post("/forgot-password", authHandler.ForgotPassword())
+	app.Get("/reset-password/:token", authHandler.ShowResetPassword())
+	app.Post("/reset-password/:token", authHandler.ResetPassword())
+
+	post := func(path string, form url.Values) *http.Response {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// followFlash loads the redirect target with the session cookie and returns its body
+	followFlash := func(resp *http.Response) string {
+		req := httptest.NewRequest("GET", resp.Header.Get("Location"), nil)
+		for _, c := range resp.Cookies() {
+			req.AddCookie(c)
+		}
+		next, err := app.Test(req)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(next.Body)
+		return string(body)
+	}
+
+	t.Run("ShowForms", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/login", nil))
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), `href="/forgot-password"`)
+
+		resp, err = app.Test(httptest.NewRequest("GET", "/reset-password/good-token", nil))
+		require.NoError(t, err)
+		body, _ = io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), `action="/reset-password/good-token"`)
+	})
+
+	t.Run("RequestDoesNotRevealAccounts", func(t *testing.T) {
+		resetRequests = nil
+
+		known := post("/forgot-password", url.Values{"email": {"test@example.com"}})
+		unknown := post("/forgot-password", url.Values{"email": {"ghost@example.com"}})
+
+		assert.Equal(t, []string{"test@example.com", "ghost@example.com"}, resetRequests)
+		for _, resp := range []*http.Response{known, unknown} {
+			assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+			assert.Equal(t, "/login", resp.Header.Get("Location"))
+			assert.Contains(t, followFlash(resp), "a reset link is on its way")
+		}
+	})
+
+	t.Run("RequestWithoutEmail", func(t *testing.T) {
+		resp := post("/forgot-password", url.Values{})
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), "email is required")
+	})
+
+	t.Run("ResetWithExpiredToken", func(t *testing.T) {
+		resp := post("/reset-password/stale-token", url.Values{"password": {"new-password"}, "passwordConfirm": {"new-password"}})
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), "This reset link is invalid or has expired")
+	})
+
+	t.Run("ResetMismatch", func(t *testing.T) {
+		resp := post("/reset-password/good-token", url.Values{"password": {"new-password"}, "passwordConfirm": {"other-password"}})
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), "passwords do not match")
+	})
+
+	t.Run("ResetSuccess", func(t *testing.T) {
+		resp := post("/reset-password/good-token", url.Values{"password": {"new-password"}, "passwordConfirm": {"new-password"}})
+
+		assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+		assert.Equal(t, "/login", resp.Header.Get("Location"))
+		assert.Contains(t, followFlash(resp), "Passcode updated")
+	})
+}
@@ -1,10 +1,19 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
 	"github.com/torresposso/gosmic/middleware"
+	"github.com/torresposso/gosmic/pb"
 	"github.com/torresposso/gosmic/services"
 	"github.com/torresposso/gosmic/views"
 
+	"github.com/a-h/templ"
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/csrf"
 	"github.com/gofiber/fiber/v3/middleware/session"
@@ -26,14 +35,31 @@ func (h *PostHandler) List() fiber.Handler {
 			return c.Redirect().To("/login")
 		}
 
-		query := c.Query("q")
-		posts, err := h.postService.List(c.Context(), client, query)
+		query := postQuery(c)
+		result, err := h.postService.List(c.Context(), client, query)
+		if errors.Is(err, pb.ErrUnavailable) {
+			return renderUnavailable(c, client)
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).SendString("Failed to load posts")
 		}
 
+		page := postsPage(query, result)
 		csrfToken := csrf.TokenFromContext(c)
-		return RenderLayout(c, "Posts", client, views.Posts(posts, csrfToken))
+		// The same URL serves whole pages and htmx fragments
+		c.Vary("HX-Request", "HX-Target")
+		if c.Get("HX-Request") == "true" && c.Get("HX-History-Restore-Request") != "true" {
+			switch c.Get("HX-Target") {
+			case "posts-pagination":
+				// "Load more": only the next posts and pagination controls
+				return Render(c, views.PostsListPage(page, csrfToken))
+			case "posts-container":
+				// Live search: the whole list, under a clean shareable URL
+				c.Set("HX-Push-Url", page.URL(page.Page))
+				return Render(c, views.PostsList(page, csrfToken))
+			}
+		}
+		return RenderLayout(c, "Posts", client, views.Posts(page, views.PostForm{}, csrfToken))
 	}
 }
 
@@ -53,6 +79,21 @@ func (h *PostHandler) Create() fiber.Handler {
 		}
 
 		err := h.postService.Create(c.Context(), client, title, content, isPublic)
+		fieldErrors := pb.FieldErrors(err)
+		if errors.Is(err, services.ErrUnverified) {
+			fieldErrors = map[string]string{"public": err.Error()}
+		}
+		if fieldErrors != nil {
+			// Re-render the form so the commander can fix the rejected fields in place
+			var page views.PostsPage
+			if result, listErr := h.postService.List(c.Context(), client, services.PostQuery{}); listErr == nil {
+				page = postsPage(services.PostQuery{}, result)
+			}
+			form := views.PostForm{Title: title, Content: content, Public: isPublic, Errors: fieldErrors}
+			csrfToken := csrf.TokenFromContext(c)
+			return RenderLayout(c, "Posts", client, views.Posts(page, form, csrfToken))
+		}
+
 		sess, _ := h.sessStore.Get(c)
 		if err != nil {
 			sess.Set("flash", "Failed to create post")
@@ -68,6 +109,22 @@ func (h *PostHandler) Create() fiber.Handler {
 	}
 }
 
+// maxPreviewLength matches the posts.content limit, so the preview endpoint
+// never renders more than a post could store
+const maxPreviewLength = 20000
+
+// Preview renders the submitted Markdown content the way the logs will show
+// it, for the live preview under the new and edit forms
+func (h *PostHandler) Preview() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		content := c.FormValue("content")
+		if len(content) > maxPreviewLength {
+			return c.Status(fiber.StatusRequestEntityTooLarge).SendString("Log too long to preview")
+		}
+		return Render(c, views.PostPreview(content))
+	}
+}
+
 func (h *PostHandler) Get() fiber.Handler {
 	return func(c fiber.Ctx) error {
 		client := middleware.GetPBClient(c)
@@ -115,6 +172,12 @@ func (h *PostHandler) Update() fiber.Handler {
 
 		err := h.postService.Update(c.Context(), client, id, title, content, isPublic)
 		sess, _ := h.sessStore.Get(c)
+		if errors.Is(err, services.ErrUnverified) {
+			sess.Set("flash", err.Error())
+			sess.Set("flash_type", "error")
+			sess.Save()
+			return c.Redirect().To("/dashboard/posts")
+		}
 		if err != nil {
 			sess.Set("flash", "Failed to update log")
 			sess.Set("flash_type", "error")
@@ -176,6 +239,9 @@ func (h *PostHandler) Toggle() fiber.Handler {
 
 		if c.Get("HX-Request") == "true" {
 			c.Set("Content-Type", "text/html")
+			if errors.Is(err, services.ErrUnverified) {
+				return views.FlashMessage(err.Error(), "error").Render(c.Context(), c.Response().BodyWriter())
+			}
 			if err != nil {
 				return views.FlashMessage("Failed to toggle visibility", "error").Render(c.Context(), c.Response().BodyWriter())
 			}
@@ -190,6 +256,9 @@ func (h *PostHandler) Toggle() fiber.Handler {
 			return views.FlashMessage("Visibility matrix updated", "success").Render(c.Context(), c.Response().BodyWriter())
 		}
 
+		if errors.Is(err, services.ErrUnverified) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to toggle visibility"})
 		}
@@ -197,3 +266,172 @@ func (h *PostHandler) Toggle() fiber.Handler {
 		return c.Redirect().To("/dashboard/posts")
 	}
 }
+
+// liveHeartbeat keeps idle event streams open through proxies and detects closed tabs
+const liveHeartbeat = 25 * time.Second
+
+// Events streams live changes to the user's visible posts as server-sent events.
+// Each "posts" event carries out-of-band HTML that htmx's SSE extension swaps into #posts-container.
+// The stream takes the list's query parameters: new posts are only prepended to the
+// default first page, other views are told to refresh instead.
+func (h *PostHandler) Events() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		client := middleware.GetPBClient(c)
+		if client == nil {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		// The stream outlives this handler, so it can't use the request-scoped
+		// context. The handshake still follows it, so a PocketBase that never
+		// answers is cut off by RequestTimeout or shutdown.
+		ctx, cancel := context.WithCancel(context.Background())
+		stop := context.AfterFunc(c.Context(), cancel)
+		events, err := h.postService.Subscribe(ctx, client)
+		stop()
+		if err == nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			cancel()
+			return c.Status(fiber.StatusBadGateway).SendString("Live updates unavailable")
+		}
+
+		query := postQuery(c)
+		csrfToken := csrf.TokenFromContext(c)
+		// Closed by fasthttp when the server shuts down
+		shutdown := c.RequestCtx().Done()
+
+		c.Set("Content-Type", "text/event-stream")
+		c.Set("Cache-Control", "no-cache")
+		c.Set("Connection", "keep-alive")
+		c.Set("X-Accel-Buffering", "no")
+
+		return c.SendStreamWriter(func(w *bufio.Writer) {
+			defer cancel()
+
+			heartbeat := time.NewTicker(liveHeartbeat)
+			defer heartbeat.Stop()
+
+			for {
+				select {
+				case ev, ok := <-events:
+					if !ok {
+						return
+					}
+					if err := writePostEvent(ctx, w, ev, query, csrfToken); err != nil {
+						return
+					}
+				case <-heartbeat.C:
+					if _, err := w.WriteString(": heartbeat\n\n"); err != nil {
+						return
+					}
+				case <-shutdown:
+					return
+				}
+
+				// Flush fails once the browser has gone away
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		})
+	}
+}
+
+// writePostEvent renders the OOB fragment for a realtime change and writes it as a "posts" SSE event
+func writePostEvent(ctx context.Context, w *bufio.Writer, ev pb.RealtimeEvent[pb.Post], query services.PostQuery, csrfToken string) error {
+	var fragment templ.Component
+	switch ev.Action {
+	case "create", "update":
+		if !query.Newest() {
+			// The post may not match, or no longer match, the search, filters,
+			// sort or page on screen
+			page := listPage(query)
+			fragment = views.PostsStaleOOB(page.URL(query.Page))
+			break
+		}
+		if ev.Action == "create" {
+			fragment = views.PostCreatedOOB(ev.Record, csrfToken)
+		} else {
+			fragment = views.PostUpdatedOOB(ev.Record, csrfToken)
+		}
+	case "delete":
+		fragment = views.PostDeletedOOB(ev.Record.ID)
+	default:
+		return nil
+	}
+
+	var html bytes.Buffer
+	if err := fragment.Render(ctx, &html); err != nil {
+		return err
+	}
+
+	w.WriteString("event: posts\n")
+	for line := range strings.SplitSeq(html.String(), "\n") {
+		w.WriteString("data: " + line + "\n")
+	}
+	_, err := w.WriteString("\n")
+	return err
+}
+
+// postQuery reads the posts list options from the query string
+func postQuery(c fiber.Ctx) services.PostQuery {
+	query := services.PostQuery{
+		Search:  c.Query("q"),
+		From:    queryDay(c, "from"),
+		To:      queryDay(c, "to"),
+		Page:    fiber.Query[int](c, "page"),
+		PerPage: fiber.Query[int](c, "perPage"),
+		After:   services.ParsePostCursor(c.Query("after")),
+	}
+	// Unknown options are dropped so page links only carry supported ones
+	if sort := services.PostSort(c.Query("sort")); sort.Valid() && sort != services.SortNewest {
+		query.Sort = sort
+	}
+	if visibility := services.PostVisibility(c.Query("visibility")); visibility.Valid() {
+		query.Visibility = visibility
+	}
+	return query
+}
+
+// postsPage describes the listed page for the view
+func postsPage(query services.PostQuery, result *pb.ListResult[pb.Post]) views.PostsPage {
+	page := listPage(query)
+	page.Posts = result.Items
+	page.Page = result.Page
+	page.TotalPages = result.TotalPages
+	if len(result.Items) > 0 {
+		page.After = query.CursorAfter(result.Items[len(result.Items)-1]).String()
+	}
+	return page
+}
+
+// listPage describes the list options of query, without any posts
+func listPage(query services.PostQuery) views.PostsPage {
+	title, content := services.SearchTerms(query.Search)
+	return views.PostsPage{
+		Query:      query.Search,
+		Sort:       string(query.Sort),
+		Visibility: string(query.Visibility),
+		From:       formatDay(query.From),
+		To:         formatDay(query.To),
+		PerPage:    query.PerPage,
+		Highlight:  views.Highlight{Title: title, Content: content},
+	}
+}
+
+// queryDay parses a YYYY-MM-DD query parameter, or returns the zero time
+func queryDay(c fiber.Ctx, key string) time.Time {
+	day, err := time.Parse(time.DateOnly, c.Query(key))
+	if err != nil {
+		return time.Time{}
+	}
+	return day
+}
+
+func formatDay(day time.Time) string {
+	if day.IsZero() {
+		return ""
+	}
+	return day.Format(time.DateOnly)
+}
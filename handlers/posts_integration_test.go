@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/middleware"
+	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/pbtest"
+	"github.com/torresposso/gosmic/repositories"
+	"github.com/torresposso/gosmic/services"
+)
+
+// TestPostsAgainstPocketBase drives login and the posts pages through the real
+// services, repositories and pb.Client against the in-process fake PocketBase.
+func TestPostsAgainstPocketBase(t *testing.T) {
+	server := pbtest.NewServer(t)
+	server.Seed(pbtest.Fixtures{
+		Users: []pbtest.User{
+			{ID: "kirk", Email: "kirk@enterprise.test", Password: "password123", Name: "James Kirk", Verified: true},
+			{ID: "spock", Email: "spock@enterprise.test", Password: "fascinating", Name: "Spock", Verified: true},
+		},
+		Posts: []pb.Post{
+			{ID: "p1", Title: "Shore leave", Author: "spock", Public: true},
+			{ID: "p2", Title: "Vulcan meditation", Author: "spock", Public: false},
+		},
+	})
+	globalClient := server.Client()
+
+	store := session.NewStore()
+	authHandler := NewAuthHandler(services.NewAuthService(repositories.NewAuthRepository()), globalClient, store, nil, nil)
+	postHandler := NewPostHandler(services.NewPostService(repositories.NewPostRepository(), services.VerificationPolicy{}), store)
+
+	app := fiber.New()
+	app.Use(middleware.MethodOverride())
+	app.Post("/login", authHandler.Login())
+	protected := app.Group("/dashboard", middleware.AuthMiddleware(globalClient))
+	protected.Get("/posts", postHandler.List())
+	protected.Post("/posts", postHandler.Create())
+	protected.Delete("/posts/:id", postHandler.Delete())
+	api := app.Group("/api", middleware.AuthMiddleware(globalClient))
+	api.Post("/posts/:id/toggle", postHandler.Toggle())
+
+	send := func(method, target string, form url.Values, cookie *http.Cookie) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := send("POST", "/login", url.Values{"email": {"kirk@enterprise.test"}, "password": {"password123"}}, nil)
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	var auth *http.Cookie
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == middleware.AuthCookie {
+			auth = cookie
+		}
+	}
+	require.NotNil(t, auth, "login should set the auth cookie")
+
+	resp = send("POST", "/dashboard/posts", url.Values{"title": {"Captain's log"}, "content": {"Stardate 41153.7"}}, auth)
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	posts := server.Posts()
+	require.Len(t, posts, 3)
+	created := posts[2]
+	assert.Equal(t, "kirk", created.Author)
+	assert.False(t, created.Public)
+
+	resp = send("GET", "/dashboard/posts", nil, auth)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "Captain&#39;s log")
+	assert.Contains(t, string(body), "Shore leave")
+	assert.NotContains(t, string(body), "Vulcan meditation", "other commanders' private logs stay hidden")
+
+	resp = send("GET", "/dashboard/posts?perPage=1&page=2", nil, auth)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "Page 2 of 2")
+	assert.Equal(t, 1, strings.Count(string(body), `class="card bg-base-200`), "one post per page")
+
+	req := httptest.NewRequest("POST", "/api/posts/"+created.ID+"/toggle", nil)
+	req.Header.Set("HX-Request", "true")
+	req.AddCookie(auth)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, server.Posts()[2].Public)
+
+	resp = send("POST", "/dashboard/posts/p1", url.Values{"_method": {"DELETE"}}, auth)
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	assert.Len(t, server.Posts(), 3, "deleting someone else's post must fail")
+
+	resp = send("POST", "/dashboard/posts/"+created.ID, url.Values{"_method": {"DELETE"}}, auth)
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	assert.Len(t, server.Posts(), 2)
+}
@@ -1,17 +1,22 @@
 package handlers
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/session"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/middleware"
 	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/repositories"
 	"github.com/torresposso/gosmic/services"
 )
 
@@ -29,13 +34,133 @@ func TestPostHandler_List(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		posts := []pb.Post{{ID: "1", Title: "Test"}}
-		mockService.On("List", mock.Anything, mock.Anything, "").Return(posts, nil).Once()
+		mockService.On("List", mock.Anything, mock.Anything, services.PostQuery{}).Return(&pb.ListResult[pb.Post]{Page: 1, TotalPages: 1, Items: posts}, nil).Once()
 
 		req := httptest.NewRequest("GET", "/posts", nil)
 		resp, err := app.Test(req)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.NotContains(t, string(body), `id="posts-pagination"`, "a single page needs no pagination")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		query := services.PostQuery{Search: "warp drive", Page: 2, PerPage: 5}
+		result := &pb.ListResult[pb.Post]{Page: 2, PerPage: 5, TotalPages: 3, Items: []pb.Post{{ID: "6", Title: "Sixth", Created: "2026-01-05 10:00:00.000Z"}}}
+		mockService.On("List", mock.Anything, mock.Anything, query).Return(result, nil).Once()
+
+		req := httptest.NewRequest("GET", "/posts?q=warp+drive&page=2&perPage=5", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		html := string(body)
+		assert.Contains(t, html, "<html")
+		assert.Contains(t, html, `id="posts-container"`)
+		assert.Contains(t, html, "Page 2 of 3")
+		assert.Contains(t, html, `href="/dashboard/posts?perPage=5&amp;q=warp+drive"`, "previous page keeps the query")
+		assert.Contains(t, html, `href="/dashboard/posts?page=3&amp;perPage=5&amp;q=warp+drive"`)
+		assert.Contains(t, html, `hx-get="/dashboard/posts?after=6%3A2026-01-05+10%3A00%3A00.000Z&amp;page=3&amp;perPage=5&amp;q=warp+drive"`,
+			"load more continues after the last post shown")
+		assert.Contains(t, html, `hx-trigger="click, revealed"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("LiveSearch", func(t *testing.T) {
+		query := services.PostQuery{Search: "warp"}
+		result := &pb.ListResult[pb.Post]{Page: 1, PerPage: 20, TotalPages: 1, Items: []pb.Post{{ID: "7", Title: "Warp trial"}}}
+		mockService.On("List", mock.Anything, mock.Anything, query).Return(result, nil).Once()
+
+		req := httptest.NewRequest("GET", "/posts?q=warp", nil)
+		req.Header.Set("HX-Request", "true")
+		req.Header.Set("HX-Target", "posts-container")
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "/dashboard/posts?q=warp", resp.Header.Get("HX-Push-Url"))
+		assert.Contains(t, resp.Header.Get("Vary"), "HX-Target")
+		body, _ := io.ReadAll(resp.Body)
+		html := string(body)
+		assert.NotContains(t, html, "<html", "only the list is swapped")
+		assert.Contains(t, html, `id="posts-container"`)
+		assert.Contains(t, html, `>Warp</mark> trial`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("SearchForm", func(t *testing.T) {
+		query := services.PostQuery{Search: `title:"core"`}
+		mockService.On("List", mock.Anything, mock.Anything, query).Return(&pb.ListResult[pb.Post]{Page: 1}, nil).Once()
+
+		req := httptest.NewRequest("GET", "/posts?q=title%3A%22core%22", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		html := string(body)
+		assert.Contains(t, html, `<form method="GET" action="/dashboard/posts" role="search"`)
+		assert.Contains(t, html, `value="title:&#34;core&#34;"`, "the box keeps the query")
+		assert.Contains(t, html, `hx-target="#posts-container"`)
+		assert.Contains(t, html, "No logs match this scan")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("SortAndFilters", func(t *testing.T) {
+		from, _ := time.Parse(time.DateOnly, "2026-01-01")
+		to, _ := time.Parse(time.DateOnly, "2026-01-31")
+		query := services.PostQuery{Sort: services.SortTitle, Visibility: services.PrivateOnly, From: from, To: to}
+		result := &pb.ListResult[pb.Post]{Page: 1, PerPage: 20, TotalPages: 2, Items: []pb.Post{{ID: "1", Title: "Alpha"}}}
+		mockService.On("List", mock.Anything, mock.Anything, query).Return(result, nil).Once()
+
+		req := httptest.NewRequest("GET", "/posts?sort=title&visibility=private&from=2026-01-01&to=2026-01-31", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		html := string(body)
+		assert.Contains(t, html, `<option value="title" selected>`)
+		assert.Contains(t, html, `<option value="private" selected>`)
+		assert.Contains(t, html, `name="from" value="2026-01-01"`)
+		assert.Contains(t, html, `name="to" value="2026-01-31"`)
+		assert.Contains(t, html, `href="/dashboard/posts?from=2026-01-01&amp;page=2&amp;sort=title&amp;to=2026-01-31&amp;visibility=private"`, "page links keep sort and filters")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("UnsupportedOptionsIgnored", func(t *testing.T) {
+		mockService.On("List", mock.Anything, mock.Anything, services.PostQuery{}).Return(&pb.ListResult[pb.Post]{Page: 1}, nil).Once()
+
+		req := httptest.NewRequest("GET", "/posts?sort=-password&visibility=all&from=yesterday&to=2026-13-01", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("LoadMore", func(t *testing.T) {
+		after := services.PostCursor{Value: "2026-01-02 08:00:00.000Z", ID: "40"}
+		query := services.PostQuery{Search: "log", Page: 3, After: after}
+		result := &pb.ListResult[pb.Post]{Page: 3, PerPage: 20, TotalPages: 3, Items: []pb.Post{{ID: "41", Title: "Last log"}}}
+		mockService.On("List", mock.Anything, mock.Anything, query).Return(result, nil).Once()
+
+		req := httptest.NewRequest("GET", "/posts?q=log&page=3&after="+url.QueryEscape(after.String()), nil)
+		req.Header.Set("HX-Request", "true")
+		req.Header.Set("HX-Target", "posts-pagination")
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		html := string(body)
+		assert.NotContains(t, html, "<html", "only the fragment is sent")
+		assert.NotContains(t, html, `id="posts-container"`)
+		assert.Contains(t, html, `id="post-41"`)
+		assert.Contains(t, html, `id="posts-pagination"`)
+		assert.NotContains(t, html, "Load more logs", "the last page stops the scroll")
 		mockService.AssertExpectations(t)
 	})
 
@@ -79,6 +204,56 @@ func TestPostHandler_Create(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("FieldErrors", func(t *testing.T) {
+		apiErr := &pb.APIError{
+			Status:  400,
+			Message: "Failed to create record.",
+			Data: map[string]pb.FieldError{
+				"title": {Code: "validation_length_out_of_range", Message: "The length must be between 1 and 120."},
+			},
+		}
+		mockService.On("Create", mock.Anything, mock.Anything, "Too Long", "Content", false).Return(apiErr).Once()
+		mockService.On("List", mock.Anything, mock.Anything, services.PostQuery{}).Return(&pb.ListResult[pb.Post]{Page: 1}, nil).Once()
+
+		form := url.Values{}
+		form.Add("title", "Too Long")
+		form.Add("content", "Content")
+
+		req := httptest.NewRequest("POST", "/posts", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		body := string(bodyBytes)
+		assert.Contains(t, body, "The length must be between 1 and 120.")
+		assert.Contains(t, body, `value="Too Long"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unverified", func(t *testing.T) {
+		mockService.On("Create", mock.Anything, mock.Anything, "Broadcast", "Content", true).Return(services.ErrUnverified).Once()
+		mockService.On("List", mock.Anything, mock.Anything, services.PostQuery{}).Return(&pb.ListResult[pb.Post]{Page: 1}, nil).Once()
+
+		form := url.Values{}
+		form.Add("title", "Broadcast")
+		form.Add("content", "Content")
+		form.Add("public", "on")
+
+		req := httptest.NewRequest("POST", "/posts", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(bodyBytes), services.ErrUnverified.Error())
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("ValidationError", func(t *testing.T) {
 		req := httptest.NewRequest("POST", "/posts", nil)
 		resp, err := app.Test(req)
@@ -204,11 +379,14 @@ func TestPostHandler_GetAndEdit(t *testing.T) {
 	})
 
 	t.Run("EditSuccess", func(t *testing.T) {
-		mockService.On("Get", mock.Anything, mock.Anything, "1").Return(&pb.Post{ID: "1", Title: "T"}, nil).Once()
+		mockService.On("Get", mock.Anything, mock.Anything, "1").Return(&pb.Post{ID: "1", Title: "T", Content: "**Red** alert"}, nil).Once()
 		req := httptest.NewRequest("GET", "/posts/1/edit", nil)
 		resp, err := app.Test(req)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), ">**Red** alert</textarea>", "the raw Markdown is edited")
+		assert.Contains(t, string(body), "<strong>Red</strong> alert", "the preview starts rendered")
 	})
 }
 
@@ -239,3 +417,150 @@ func TestPostHandler_Update(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestPostHandler_Preview(t *testing.T) {
+	app := fiber.New()
+	handler := NewPostHandler(new(services.MockPostService), session.NewStore())
+	app.Post("/posts/preview", handler.Preview())
+
+	preview := func(content string) (*http.Response, string) {
+		t.Helper()
+		form := url.Values{"content": {content}}
+		req := httptest.NewRequest("POST", "/posts/preview", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	t.Run("Markdown", func(t *testing.T) {
+		resp, body := preview("# Log\n\n*Warp* [core](https://example.com)<script>alert(1)</script>")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotContains(t, body, "<html", "a fragment for the preview panel")
+		assert.Contains(t, body, "<h1>Log</h1>")
+		assert.Contains(t, body, "<em>Warp</em>")
+		assert.Contains(t, body, `<a href="https://example.com" rel="nofollow noreferrer">core</a>`)
+		assert.NotContains(t, body, "<script")
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		resp, body := preview("  ")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, body, "Nothing to preview yet.")
+	})
+
+	t.Run("TooLong", func(t *testing.T) {
+		resp, _ := preview(strings.Repeat("a", maxPreviewLength+1))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+}
+
+func TestPostHandler_Events(t *testing.T) {
+	app := fiber.New()
+	mockService := new(services.MockPostService)
+	store := session.NewStore()
+	handler := NewPostHandler(mockService, store)
+
+	app.Get("/posts/events", func(c fiber.Ctx) error {
+		c.Locals("pb", &pb.Client{})
+		return handler.Events()(c)
+	})
+
+	t.Run("StreamsOOBFragments", func(t *testing.T) {
+		// Fake event source: a pre-filled channel that ends the stream once drained
+		events := make(chan pb.RealtimeEvent[pb.Post], 3)
+		events <- pb.RealtimeEvent[pb.Post]{Action: "create", Record: pb.Post{ID: "p1", Title: "New Contact"}}
+		events <- pb.RealtimeEvent[pb.Post]{Action: "update", Record: pb.Post{ID: "p2", Title: "Revised Contact", Public: true}}
+		events <- pb.RealtimeEvent[pb.Post]{Action: "delete", Record: pb.Post{ID: "p3"}}
+		close(events)
+
+		mockService.On("Subscribe", mock.Anything, mock.Anything).Return((<-chan pb.RealtimeEvent[pb.Post])(events), nil).Once()
+
+		req := httptest.NewRequest("GET", "/posts/events", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		body := string(bodyBytes)
+
+		chunks := strings.Split(strings.TrimSpace(body), "\n\n")
+		assert.Len(t, chunks, 3)
+		for _, chunk := range chunks {
+			for _, line := range strings.Split(chunk, "\n")[1:] {
+				assert.True(t, strings.HasPrefix(line, "data: "), "unexpected SSE line %q", line)
+			}
+			assert.True(t, strings.HasPrefix(chunk, "event: posts\n"))
+		}
+
+		assert.Contains(t, chunks[0], `hx-swap-oob="afterbegin:#posts-container"`)
+		assert.Contains(t, chunks[0], "New Contact")
+		assert.Contains(t, chunks[1], `hx-swap-oob="outerHTML"`)
+		assert.Contains(t, chunks[1], `id="post-p2"`)
+		assert.Contains(t, chunks[1], "Broadcasted")
+		assert.Contains(t, chunks[2], `id="post-p3" hx-swap-oob="delete"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("FilteredViewRefreshes", func(t *testing.T) {
+		events := make(chan pb.RealtimeEvent[pb.Post], 2)
+		events <- pb.RealtimeEvent[pb.Post]{Action: "create", Record: pb.Post{ID: "p1", Title: "Unrelated contact"}}
+		// Edited away from the search term, so it must not stay on screen as is
+		events <- pb.RealtimeEvent[pb.Post]{Action: "update", Record: pb.Post{ID: "p2", Title: "Impulse drive"}}
+		close(events)
+		mockService.On("Subscribe", mock.Anything, mock.Anything).Return((<-chan pb.RealtimeEvent[pb.Post])(events), nil).Once()
+
+		req := httptest.NewRequest("GET", "/posts/events?q=warp&sort=title&page=2", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		body := string(bodyBytes)
+		assert.NotContains(t, body, "Unrelated contact", "the new post may not match the search")
+		assert.NotContains(t, body, "afterbegin:#posts-container")
+		assert.NotContains(t, body, "Impulse drive", "the edited post may no longer match the search")
+		assert.NotContains(t, body, `id="post-p2"`)
+		assert.Equal(t, 2, strings.Count(body, `id="posts-stale" hx-swap-oob="true"`))
+		assert.Contains(t, body, `id="posts-stale" hx-swap-oob="true"`)
+		assert.Contains(t, body, `hx-get="/dashboard/posts?page=2&amp;q=warp&amp;sort=title"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("SilentPocketBaseTimesOut", func(t *testing.T) {
+		// Accepts the realtime stream but never sends PB_CONNECT
+		pbServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer pbServer.Close()
+
+		app := fiber.New()
+		app.Use(middleware.RequestTimeout(100 * time.Millisecond))
+		handler := NewPostHandler(services.NewPostService(repositories.NewPostRepository(), services.VerificationPolicy{}), store)
+		app.Get("/posts/events", func(c fiber.Ctx) error {
+			c.Locals("pb", pb.NewClient(pbServer.URL))
+			return handler.Events()(c)
+		})
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/posts/events", nil), fiber.TestConfig{Timeout: 2 * time.Second})
+
+		require.NoError(t, err, "the handshake must not outlive the request timeout")
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	})
+
+	t.Run("SubscribeError", func(t *testing.T) {
+		mockService.On("Subscribe", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+
+		req := httptest.NewRequest("GET", "/posts/events", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+}
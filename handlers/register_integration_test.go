@@ -14,6 +14,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/extractors"
 	"github.com/gofiber/fiber/v3/middleware/csrf"
+	"github.com/gofiber/fiber/v3/middleware/session"
 	"github.com/stretchr/testify/assert"
 	"github.com/torresposso/gosmic/pb"
 	"github.com/torresposso/gosmic/repositories"
@@ -53,7 +54,7 @@ func TestRegisterFlow(t *testing.T) {
 	// Initialize Services and Handlers for testing
 	authRepo := repositories.NewAuthRepository()
 	authService := services.NewAuthService(authRepo)
-	authHandler := NewAuthHandler(authService, pbClient)
+	authHandler := NewAuthHandler(authService, pbClient, session.NewStore(), nil, nil)
 
 	app.Get("/register", authHandler.ShowRegister())
 	app.Post("/register", authHandler.Register())
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/middleware"
+	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/pbtest"
+	"github.com/torresposso/gosmic/repositories"
+	"github.com/torresposso/gosmic/services"
+)
+
+// TestEmailVerificationFlow registers against the fake PocketBase, which
+// delivers the verification email over SMTP to a local mail server, and
+// checks that PocketBase itself keeps public posts from unverified accounts.
+func TestEmailVerificationFlow(t *testing.T) {
+	mail := pbtest.NewMailServer(t)
+	server := pbtest.NewServer(t)
+	server.SendMailTo(mail, "http://localhost:8080")
+	globalClient := server.Client()
+
+	store := session.NewStore()
+	app := fiber.New()
+	app.Use(middleware.FlashMiddleware(store))
+	authHandler := NewAuthHandler(services.NewAuthService(repositories.NewAuthRepository()), globalClient, store, nil, nil)
+	app.Post("/register", authHandler.Register())
+	app.Get("/login", authHandler.ShowLogin())
+	app.Post("/login", authHandler.Login())
+	app.Get("/verify/:token", authHandler.VerifyEmail())
+	app.Post("/dashboard/verify/resend", middleware.AuthMiddleware(globalClient), authHandler.ResendVerification())
+
+	send := func(method, target string, form url.Values, cookie *http.Cookie) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+	linkPattern := regexp.MustCompile(`http://localhost:8080(/verify/\S+)`)
+	link := func(msg pbtest.Message) string {
+		t.Helper()
+		match := linkPattern.FindStringSubmatch(msg.Body)
+		require.NotNil(t, match, "no verification link in %q", msg.Body)
+		return match[1]
+	}
+
+	resp := send("POST", "/register", url.Values{
+		"email":           {"new@example.com"},
+		"name":            {"New Recruit"},
+		"password":        {"password123"},
+		"passwordConfirm": {"password123"},
+	}, nil)
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	inbox := mail.Inbox("new@example.com")
+	require.Len(t, inbox, 1, "registering sends the verification email")
+	assert.Equal(t, "Verify your email", inbox[0].Subject)
+
+	resp = send("POST", "/login", url.Values{"email": {"new@example.com"}, "password": {"password123"}}, nil)
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	var auth *http.Cookie
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == middleware.AuthCookie {
+			auth = cookie
+		}
+	}
+	require.NotNil(t, auth, "unverified users may sign in")
+	claims, err := pb.ParseToken(auth.Value)
+	require.NoError(t, err)
+	userID := claims.ID
+	direct := server.AuthClient(userID)
+
+	t.Run("UnverifiedCannotPublish", func(t *testing.T) {
+		// Straight to PocketBase, past the app's own policy check
+		err := direct.CreatePostContext(context.Background(), "Broadcast", "", true)
+		assert.Error(t, err, "the posts create rule requires a verified email")
+		require.NoError(t, direct.CreatePostContext(context.Background(), "Diary", "", false), "private posts stay open")
+
+		posts := server.Posts()
+		require.Len(t, posts, 1)
+		err = direct.UpdatePostContext(context.Background(), posts[0].ID, map[string]any{"public": true})
+		assert.Error(t, err, "the posts update rule requires a verified email")
+		assert.False(t, server.Posts()[0].Public)
+	})
+
+	t.Run("Resend", func(t *testing.T) {
+		resp := send("POST", "/dashboard/verify/resend", nil, auth)
+		assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+		assert.Equal(t, "/dashboard", resp.Header.Get("Location"))
+		assert.Len(t, mail.Inbox("new@example.com"), 2)
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		resp := send("GET", "/verify/forged", nil, auth)
+		assert.Equal(t, "/dashboard", resp.Header.Get("Location"))
+
+		// The flash is shown on the next page
+		next := httptest.NewRequest("GET", "/login", nil)
+		for _, c := range resp.Cookies() {
+			next.AddCookie(c)
+		}
+		nextResp, err := app.Test(next)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(nextResp.Body)
+		assert.Contains(t, string(body), "This verification link is invalid or has expired")
+		assert.False(t, server.User(userID).Verified)
+	})
+
+	t.Run("ConfirmUnlocksPublishing", func(t *testing.T) {
+		inbox := mail.Inbox("new@example.com")
+		require.NotEmpty(t, inbox)
+
+		// Opened in a browser without a session
+		resp := send("GET", link(inbox[len(inbox)-1]), nil, nil)
+		assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+		assert.Equal(t, "/login", resp.Header.Get("Location"))
+		assert.True(t, server.User(userID).Verified)
+
+		assert.NoError(t, direct.CreatePostContext(context.Background(), "Broadcast", "", true))
+	})
+}
+
+// TestVerifyEmailRefreshesCachedUser checks that confirming the email takes
+// effect at once instead of after AuthMiddleware's record cache expires.
+func TestVerifyEmailRefreshesCachedUser(t *testing.T) {
+	verified := false
+	pocketbase := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/collections/users/records/user123":
+			json.NewEncoder(w).Encode(map[string]any{"id": "user123", "email": "new@example.com", "verified": verified})
+		case "/api/collections/users/confirm-verification":
+			verified = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer pocketbase.Close()
+
+	token := func(typ string) string {
+		claims, _ := json.Marshal(map[string]any{"id": "user123", "type": typ, "exp": time.Now().Add(48 * time.Hour).Unix()})
+		return "header." + base64.RawURLEncoding.EncodeToString(claims) + ".signature"
+	}
+	// A verification link opened in a browser with no session
+	verificationToken := token("verification")
+	sessionToken := token("auth")
+
+	pbClient := pb.NewClient(pocketbase.URL)
+	users := middleware.NewUserCache(time.Minute)
+	authHandler := NewAuthHandler(services.NewAuthService(repositories.NewAuthRepository()), pbClient, session.NewStore(), nil, users)
+
+	app := fiber.New()
+	app.Get("/verify/:token", authHandler.VerifyEmail())
+	app.Get("/dashboard", middleware.AuthMiddleware(pbClient, middleware.AuthConfig{Users: users}), func(c fiber.Ctx) error {
+		return c.JSON(fiber.Map{"verified": middleware.GetPBClient(c).IsVerified()})
+	})
+
+	isVerified := func() bool {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/dashboard", nil)
+		req.AddCookie(&http.Cookie{Name: middleware.AuthCookie, Value: sessionToken})
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		var body map[string]bool
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body["verified"]
+	}
+
+	assert.False(t, isVerified(), "the unverified record is now cached")
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/verify/"+verificationToken, nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+
+	assert.True(t, isVerified(), "the cached record was dropped")
+}
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/extractors"
 	"github.com/gofiber/fiber/v3/middleware/compress"
@@ -12,11 +19,13 @@ import (
 	"github.com/gofiber/fiber/v3/middleware/limiter"
 	"github.com/gofiber/fiber/v3/middleware/logger"
 	"github.com/gofiber/fiber/v3/middleware/recover"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
 	"github.com/gofiber/fiber/v3/middleware/session"
 	"github.com/gofiber/fiber/v3/middleware/static"
 
 	"github.com/torresposso/gosmic/handlers"
 	"github.com/torresposso/gosmic/middleware"
+	"github.com/torresposso/gosmic/migrations"
 	"github.com/torresposso/gosmic/pb"
 	"github.com/torresposso/gosmic/repositories"
 	"github.com/torresposso/gosmic/services"
@@ -28,8 +37,26 @@ func main() {
 	baseURL := getEnv("BASE_URL", "http://localhost:"+port)
 	isProd := os.Getenv("GO_ENV") == "production"
 
-	// Create a global PocketBase client (connection pool is shared)
-	globalClient := pb.NewClient(pbURL)
+	// Create a global PocketBase client (connection pool is shared).
+	// Every call carries the request's ID; outside production each call is logged too.
+	pbMiddlewares := []pb.Middleware{pb.RequestID()}
+	if !isProd {
+		pbMiddlewares = append(pbMiddlewares, pb.Logging(log.Default()))
+	}
+	globalClient := pb.NewClient(pbURL, pb.WithMiddleware(pbMiddlewares...))
+
+	// `app migrate [status]` applies (or lists) pending schema migrations and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(context.Background(), globalClient, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if os.Getenv("PB_MIGRATE") == "true" {
+		if err := migrate(context.Background(), globalClient, nil); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	app := fiber.New(fiber.Config{
 		AppName:       "Fiber v3 + PocketBase Tutorial",
@@ -42,9 +69,13 @@ func main() {
 	app.Use(recover.New())
 	app.Use(compress.New(compress.Config{
 		Level: compress.LevelBestSpeed,
+		// Compression buffers the whole body, which would stall event streams
+		Next: func(c fiber.Ctx) bool {
+			return c.Get(fiber.HeaderAccept) == "text/event-stream"
+		},
 	}))
 	app.Use(logger.New(logger.Config{
-		Format:     "${time} | ${status} | ${latency} | ${method} ${path}\n",
+		Format:     "${time} | ${status} | ${latency} | ${respHeader:X-Request-ID} | ${method} ${path}\n",
 		TimeFormat: "2006-01-02 15:04:05",
 	}))
 
@@ -53,7 +84,13 @@ func main() {
 		Expiration: 1 * time.Minute,
 	}))
 
-	// Static files
+	// Bound every request (and the PocketBase calls it makes) by a deadline
+	app.Use(middleware.RequestTimeout(15 * time.Second))
+
+	// Tag each request with an ID that is forwarded to PocketBase
+	app.Use(requestid.New())
+	app.Use(middleware.PropagateRequestID())
+
 	// Static files
 	app.Use("/static", static.New("./static", static.Config{
 		Compress: true,
@@ -82,13 +119,18 @@ func main() {
 	authRepo := repositories.NewAuthRepository()
 
 	// Initialize Services
-	postService := services.NewPostService(postRepo)
+	postService := services.NewPostService(postRepo, services.VerificationPolicy{
+		AllowPublicPosts: os.Getenv("ALLOW_UNVERIFIED_PUBLIC") == "true",
+	})
 	authService := services.NewAuthService(authRepo)
 	docService := services.NewDocService("./chapters")
+	passkeyService := newPasskeyService(globalClient, baseURL)
 
 	// Initialize Handlers
 	postHandler := handlers.NewPostHandler(postService, sessStore)
-	authHandler := handlers.NewAuthHandler(authService, globalClient)
+	// One record cache for every AuthMiddleware, so handlers can invalidate it
+	users := middleware.NewUserCache(time.Minute)
+	authHandler := handlers.NewAuthHandler(authService, globalClient, sessStore, passkeyService, users)
 	rootHandler := handlers.NewRootHandler(globalClient, postService)
 	docHandler := handlers.NewDocHandler(docService, globalClient)
 
@@ -98,27 +140,122 @@ func main() {
 	app.Get("/docs/:chapter", docHandler.Show())
 	app.Get("/login", authHandler.ShowLogin())
 	app.Post("/login", authHandler.Login())
+	app.Get("/login/otp", authHandler.ShowOTP())
+	app.Post("/login/otp", authHandler.VerifyOTP())
+	app.Post("/login/otp/resend", authHandler.ResendOTP())
+	app.Post("/login/passkey/begin", authHandler.BeginPasskeyLogin())
+	app.Post("/login/passkey/finish", authHandler.FinishPasskeyLogin())
 	app.Get("/register", authHandler.ShowRegister())
 	app.Post("/register", authHandler.Register())
+	app.Get("/auth/oauth2/callback", authHandler.OAuth2Callback())
+	app.Get("/auth/oauth2/:provider", authHandler.OAuth2Redirect())
 	app.Get("/logout", authHandler.Logout())
+	app.Get("/forgot-password", authHandler.ShowForgotPassword())
+	app.Post("/forgot-password", authHandler.ForgotPassword())
+	app.Get("/reset-password/:token", authHandler.ShowResetPassword())
+	app.Post("/reset-password/:token", authHandler.ResetPassword())
+	app.Get("/verify/:token", authHandler.VerifyEmail())
 
 	// Protected routes - middleware creates request-scoped client
-	protected := app.Group("/dashboard", middleware.AuthMiddleware(globalClient))
+	protected := app.Group("/dashboard", middleware.AuthMiddleware(globalClient, middleware.AuthConfig{Users: users}))
 	protected.Get("", rootHandler.Dashboard())
+	protected.Post("/verify/resend", authHandler.ResendVerification())
+	protected.Get("/passkeys", authHandler.ShowPasskeys())
+	protected.Post("/passkeys/register/begin", authHandler.BeginPasskeyRegistration())
+	protected.Post("/passkeys/register/finish", authHandler.FinishPasskeyRegistration())
 	protected.Get("/posts", postHandler.List())
 	protected.Post("/posts", postHandler.Create())
+	protected.Post("/posts/preview", postHandler.Preview())
+	protected.Get("/posts/events", postHandler.Events())
 	protected.Get("/posts/:id", postHandler.Get())
 	protected.Get("/posts/:id/edit", postHandler.Edit())
 	protected.Put("/posts/:id", postHandler.Update())
 	protected.Delete("/posts/:id", postHandler.Delete())
 
 	// API routes
-	api := app.Group("/api", middleware.AuthMiddleware(globalClient))
+	api := app.Group("/api", middleware.AuthMiddleware(globalClient, middleware.AuthConfig{Users: users}))
 	api.Post("/posts/:id/toggle", postHandler.Toggle())
 
 	log.Printf("Server starting on %s", baseURL)
 	log.Printf("PocketBase: %s", pbURL)
-	log.Fatal(app.Listen(":" + port))
+
+	// Cancel in-flight requests and their PocketBase calls on shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := app.Listen(":"+port, fiber.ListenConfig{
+		GracefulContext: ctx,
+	}); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newPasskeyService enables passkey sign-in when superuser credentials are set.
+// Passkeys are bound to BASE_URL, so it must be the address users browse to.
+func newPasskeyService(globalClient *pb.Client, baseURL string) services.PasskeyService {
+	email, password := os.Getenv("PB_SUPERUSER_EMAIL"), os.Getenv("PB_SUPERUSER_PASSWORD")
+	if email == "" || password == "" {
+		log.Printf("Passkeys disabled: PB_SUPERUSER_EMAIL and PB_SUPERUSER_PASSWORD are not set")
+		return nil
+	}
+
+	origin, err := url.Parse(baseURL)
+	if err != nil {
+		log.Fatalf("invalid BASE_URL: %v", err)
+	}
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          origin.Hostname(),
+		RPDisplayName: "Gosmic Code",
+		RPOrigins:     []string{origin.Scheme + "://" + origin.Host},
+	})
+	if err != nil {
+		log.Fatalf("invalid passkey configuration: %v", err)
+	}
+
+	superuser := pb.NewSuperuser(globalClient, email, password)
+	return services.NewPasskeyService(wa, repositories.NewPasskeyRepository(superuser))
+}
+
+// migrate applies the pending schema migrations as the PB_SUPERUSER_* account.
+// With the "status" argument it only lists them.
+func migrate(ctx context.Context, globalClient *pb.Client, args []string) error {
+	statusOnly := len(args) == 1 && args[0] == "status"
+	if len(args) > 0 && !statusOnly {
+		return errors.New("usage: app migrate [status]")
+	}
+
+	email, password := os.Getenv("PB_SUPERUSER_EMAIL"), os.Getenv("PB_SUPERUSER_PASSWORD")
+	if email == "" || password == "" {
+		return errors.New("migrations need PB_SUPERUSER_EMAIL and PB_SUPERUSER_PASSWORD")
+	}
+	client, err := pb.NewSuperuser(globalClient, email, password).Client(ctx)
+	if err != nil {
+		return fmt.Errorf("superuser sign-in failed: %w", err)
+	}
+
+	if statusOnly {
+		pending, err := migrations.Pending(ctx, client, migrations.All)
+		if err != nil {
+			return err
+		}
+		for _, m := range pending {
+			log.Printf("Pending migration %d: %s", m.Version, m.Name)
+		}
+		log.Printf("%d of %d migrations pending", len(pending), len(migrations.All))
+		return nil
+	}
+
+	applied, err := migrations.Run(ctx, client, migrations.All)
+	for _, m := range applied {
+		log.Printf("Applied migration %d: %s", m.Version, m.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		log.Printf("PocketBase schema is up to date")
+	}
+	return nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -0,0 +1,52 @@
+// Package markdown renders user-authored Markdown, such as mission log
+// contents, to HTML that is safe to embed in a page.
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// md converts GitHub Flavored Markdown. Raw HTML in the source is omitted and
+// dangerous link schemes are dropped by goldmark itself; Sanitize is applied
+// on top so the output never depends on the renderer getting that right.
+var md = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(
+		gmhtml.WithHardWraps(),
+		// Table alignment as align attributes rather than inline styles
+		gmhtml.WithXHTML(),
+	),
+)
+
+// Span is a run of text; Marked spans are wrapped in <mark>.
+type Span struct {
+	Text   string
+	Marked bool
+}
+
+// Render converts Markdown source to sanitized HTML. When mark is not nil it
+// splits the text of the rendered document, so search matches can be
+// highlighted without touching the markup around them.
+func Render(source string, mark func(text string) []Span) string {
+	if strings.TrimSpace(source) == "" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(source), &buf); err != nil {
+		// goldmark only fails on writer errors; fall back to the plain text
+		return "<p>" + html.EscapeString(source) + "</p>"
+	}
+	return sanitize(buf.String(), mark)
+}
+
+// Sanitize strips everything but a whitelist of formatting elements and
+// attributes from an HTML fragment.
+func Sanitize(fragment string) string {
+	return sanitize(fragment, nil)
+}
@@ -0,0 +1,103 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"Empty", "  \n", ""},
+		{"Paragraph", "Stardate *41153.7*", "<p>Stardate <em>41153.7</em></p>\n"},
+		{"HardWraps", "line\nbreak", "<p>line<br/>\nbreak</p>\n"},
+		{"Heading", "## Sensor sweep", "<h2>Sensor sweep</h2>\n"},
+		{"Code", "```go\nfmt.Println(\"<hi>\")\n```", "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;hi&gt;&#34;)\n</code></pre>\n"},
+		{"Link", "[bridge](https://example.com/a?b=1&c=2)", `<p><a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noreferrer">bridge</a></p>` + "\n"},
+		{"Autolink", "see https://example.com", `<p>see <a href="https://example.com" rel="nofollow noreferrer">https://example.com</a></p>` + "\n"},
+		{"Image", `![nebula](https://example.com/n.png "Crab")`, `<p><img src="https://example.com/n.png" alt="nebula" title="Crab"/></p>` + "\n"},
+		{"TaskList", "- [x] warp\n- [ ] dock", "<ul>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"/> warp</li>\n<li><input disabled=\"\" type=\"checkbox\"/> dock</li>\n</ul>\n"},
+		{"Table", "| a | b |\n|:-|-:|\n| 1 | 2 |", "<table>\n<thead>\n<tr>\n<th align=\"left\">a</th>\n<th align=\"right\">b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td align=\"left\">1</td>\n<td align=\"right\">2</td>\n</tr>\n</tbody>\n</table>\n"},
+		{"Strikethrough", "~~aborted~~", "<p><del>aborted</del></p>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Render(tt.source, nil))
+		})
+	}
+}
+
+func TestRenderStripsScripts(t *testing.T) {
+	payloads := []string{
+		"<script>alert(1)</script>",
+		"<img src=x onerror=alert(1)>",
+		`<a href="javascript:alert(1)">x</a>`,
+		"[x](javascript:alert(1))",
+		"[x](JaVaScRiPt:alert(1))",
+		"[x](java&#x09;script:alert(1))",
+		"[x](data:text/html;base64,PHNjcmlwdD4=)",
+		"![x](javascript:alert(1))",
+		`<iframe src="https://evil.test"></iframe>`,
+		`<svg onload=alert(1)>`,
+		"<div style=\"background:url(javascript:alert(1))\">x</div>",
+	}
+	for _, payload := range payloads {
+		t.Run(payload, func(t *testing.T) {
+			outputs := []string{Render(payload, nil)}
+			if strings.HasPrefix(payload, "<") {
+				// Sanitize sees the raw HTML that goldmark would already omit
+				outputs = append(outputs, Sanitize(payload))
+			}
+			for _, out := range outputs {
+				out = strings.ToLower(out)
+				for _, bad := range []string{"<script", "javascript:", "onerror", "onload", "<iframe", "<svg", "data:", "style="} {
+					assert.NotContains(t, out, bad)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		want     string
+	}{
+		{"Plain", "a < b", "a &lt; b"},
+		{"Unwrap", `<div class="x" id="posts-container"><b>bold</b></div>`, "<b>bold</b>"},
+		{"DropContent", "<p>ok<script>alert(1)</script><style>p{}</style></p>", "<p>ok</p>"},
+		{"Comment", "<!-- hidden -->shown", "shown"},
+		{"EventHandler", `<p onclick="alert(1)">x</p>`, "<p>x</p>"},
+		{"UnsafeHref", `<a href="vbscript:msgbox">x</a>`, "x"},
+		{"RelativeHref", `<a href="/dashboard/posts">logs</a>`, `<a href="/dashboard/posts" rel="nofollow noreferrer">logs</a>`},
+		{"Mailto", `<a href="mailto:kirk@enterprise.test">mail</a>`, `<a href="mailto:kirk@enterprise.test" rel="nofollow noreferrer">mail</a>`},
+		{"CodeClass", `<code class="language-go evil">x</code>`, "<code>x</code>"},
+		{"TextInput", `<input type="text" value="x">`, ""},
+		{"OlStart", `<ol start="3" type="a"><li>c</li></ol>`, `<ol start="3"><li>c</li></ol>`},
+		{"AttributeQuotes", `<img src="https://example.com/x.png" alt='a"b'>`, `<img src="https://example.com/x.png" alt="a&#34;b">`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Sanitize(tt.fragment))
+		})
+	}
+}
+
+func TestRenderMark(t *testing.T) {
+	mark := func(text string) []Span {
+		before, after, ok := strings.Cut(text, "warp")
+		if !ok {
+			return []Span{{Text: text}}
+		}
+		return []Span{{Text: before}, {Text: "warp", Marked: true}, {Text: after}}
+	}
+
+	out := Render("**warp** core, [warp](https://example.com/warp) <b>", mark)
+	assert.Equal(t, `<p><strong><mark>warp</mark></strong> core, <a href="https://example.com/warp" rel="nofollow noreferrer"><mark>warp</mark></a> </p>`+"\n", out, "only text is marked, never attributes")
+}
@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+)
+
+// policy keeps the formatting elements goldmark produces. It is built from
+// bluemonday's building blocks rather than UGCPolicy, which also allows id
+// attributes that could clobber the page's own htmx targets.
+var policy = func() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	// Relative, http, https and mailto URLs, with rel="nofollow" on links
+	p.AllowStandardURLs()
+	p.RequireNoReferrerOnLinks(true)
+	p.AllowElements(
+		"p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6",
+		"strong", "b", "em", "i", "del", "s", "sub", "sup", "kbd",
+		"pre", "code", "blockquote", "ul", "ol", "li",
+		"table", "thead", "tbody", "tr", "th", "td",
+	)
+	p.AllowAttrs("href", "title").OnElements("a")
+	p.AllowAttrs("src", "alt", "title").OnElements("img")
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	// Only the language hint of fenced code blocks
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	// GFM task list checkboxes
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+	return p
+}()
+
+// sanitize strips fragment down to the policy, passing text through mark when
+// it is not nil.
+func sanitize(fragment string, mark func(text string) []Span) string {
+	clean := policy.Sanitize(fragment)
+	if mark == nil {
+		return clean
+	}
+	return markText(clean, mark)
+}
+
+// markText wraps the spans mark reports in the text of an already sanitized
+// fragment, leaving its markup as is.
+func markText(fragment string, mark func(text string) []Span) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(fragment))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return b.String()
+		case html.TextToken:
+			for _, span := range mark(string(z.Text())) {
+				if span.Marked {
+					b.WriteString("<mark>" + html.EscapeString(span.Text) + "</mark>")
+				} else {
+					b.WriteString(html.EscapeString(span.Text))
+				}
+			}
+		default:
+			b.Write(z.Raw())
+		}
+	}
+}
@@ -1,37 +1,124 @@
 package middleware
 
 import (
-	"encoding/base64"
-	"encoding/json"
-	"strings"
+	"errors"
+	"log"
+	"time"
 
 	"github.com/torresposso/gosmic/pb"
 
 	"github.com/gofiber/fiber/v3"
 )
 
+// AuthCookie is the name of the cookie holding the PocketBase auth token.
+const AuthCookie = "pb_auth"
+
+// AuthConfig tunes AuthMiddleware.
+type AuthConfig struct {
+	// RefreshWindow triggers a token refresh when the token expires within this duration.
+	// Optional. Default: 24 hours
+	RefreshWindow time.Duration
+
+	// UserCacheTTL is how long a fetched user record is reused before it is loaded again.
+	// Optional. Default: 1 minute
+	UserCacheTTL time.Duration
+
+	// Users caches fetched user records; share one between middleware instances and the
+	// handlers that need to invalidate records. Optional. Default: a new cache with UserCacheTTL
+	Users *UserCache
+
+	// Now returns the current time. Optional. Default: time.Now
+	Now func() time.Time
+}
+
+func authConfigDefault(config ...AuthConfig) AuthConfig {
+	cfg := AuthConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.RefreshWindow <= 0 {
+		cfg.RefreshWindow = 24 * time.Hour
+	}
+	if cfg.UserCacheTTL <= 0 {
+		cfg.UserCacheTTL = time.Minute
+	}
+	if cfg.Users == nil {
+		cfg.Users = NewUserCache(cfg.UserCacheTTL)
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return cfg
+}
+
 // AuthMiddleware validates the PocketBase token from cookie and creates a request-scoped client.
-func AuthMiddleware(globalClient *pb.Client) fiber.Handler {
+// Expired tokens are rejected, and tokens close to expiry are refreshed with PocketBase and
+// the cookie reissued, so handlers never talk to PocketBase with a dead token.
+//
+// The client's AuthRecord is populated with the full user record (name, email, avatar,
+// verification status), cached briefly per token to avoid a lookup on every request.
+func AuthMiddleware(globalClient *pb.Client, config ...AuthConfig) fiber.Handler {
+	cfg := authConfigDefault(config...)
+	users := cfg.Users
+
 	return func(c fiber.Ctx) error {
-		token := c.Cookies("pb_auth")
+		token := c.Cookies(AuthCookie)
 		if token == "" {
-			return c.Redirect().To("/login")
+			return redirectToLogin(c)
 		}
 
 		// Create a request-scoped client with the user's token
 		userClient := globalClient.WithToken(token)
 
-		// Extract User ID from JWT (Payload is the 2nd part)
 		// We trust PocketBase to verify the signature on the next request.
-		// We just need the ID to form the request correctly.
-		parts := strings.Split(token, ".")
-		if len(parts) == 3 {
-			payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
-			var claims struct {
-				ID string `json:"id"`
+		// Tokens we cannot decode are passed through for PocketBase to judge.
+		if claims, err := pb.ParseToken(token); err == nil {
+			now := cfg.Now()
+			if claims.Expired(now) {
+				ClearAuthCookie(c)
+				return redirectToLogin(c)
+			}
+
+			// Impersonation tokens (passkey logins) cannot be refreshed; they
+			// stay valid until they expire
+			if claims.ExpiresWithin(now, cfg.RefreshWindow) && claims.CanRefresh() {
+				newToken, user, err := userClient.AuthRefresh(c.Context())
+				switch {
+				case err == nil:
+					SetAuthCookie(c, newToken)
+					userClient = globalClient.WithToken(newToken)
+					userClient.AuthRecord = user
+					if user != nil && user.ID != "" {
+						users.set(newToken, user, now)
+					}
+				case errors.Is(err, pb.ErrUnauthorized), errors.Is(err, pb.ErrForbidden), errors.Is(err, pb.ErrNotFound):
+					// PocketBase no longer accepts the token (revoked user, changed password...)
+					ClearAuthCookie(c)
+					return redirectToLogin(c)
+				default:
+					// Transient failure: the current token is still valid, try again next request
+					log.Printf("auth refresh failed: %v", err)
+				}
 			}
-			if err := json.Unmarshal(payload, &claims); err == nil && claims.ID != "" {
-				userClient.AuthRecord = &pb.User{ID: claims.ID}
+
+			if userClient.AuthRecord == nil && claims.ID != "" {
+				if user, ok := users.get(token, now); ok {
+					userClient.AuthRecord = user
+				} else {
+					user, err := userClient.Users().Get(c.Context(), claims.ID)
+					switch {
+					case err == nil:
+						users.set(token, user, now)
+						userClient.AuthRecord = user
+					case errors.Is(err, pb.ErrUnauthorized), errors.Is(err, pb.ErrForbidden), errors.Is(err, pb.ErrNotFound):
+						ClearAuthCookie(c)
+						return redirectToLogin(c)
+					default:
+						// Fall back to what the token tells us so the request can still proceed
+						log.Printf("auth record lookup failed: %v", err)
+						userClient.AuthRecord = &pb.User{ID: claims.ID}
+					}
+				}
 			}
 		}
 
@@ -50,3 +137,30 @@ func GetPBClient(c fiber.Ctx) *pb.Client {
 	}
 	return nil
 }
+
+// SetAuthCookie stores the PocketBase token in an HttpOnly cookie.
+func SetAuthCookie(c fiber.Ctx, token string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     AuthCookie,
+		Value:    token,
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https", // Automatically detect
+		SameSite: "Lax",
+		Path:     "/",
+	})
+}
+
+// ClearAuthCookie removes the PocketBase token cookie.
+func ClearAuthCookie(c fiber.Ctx) {
+	c.ClearCookie(AuthCookie)
+}
+
+// redirectToLogin sends the user to /login. htmx requests get an HX-Redirect
+// so the whole page navigates instead of swapping the login form into a fragment.
+func redirectToLogin(c fiber.Ctx) error {
+	if c.Get("HX-Request") == "true" {
+		c.Set("HX-Redirect", "/login")
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	return c.Redirect().To("/login")
+}
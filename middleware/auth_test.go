@@ -3,9 +3,11 @@ package middleware
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/stretchr/testify/assert"
@@ -74,3 +76,285 @@ func TestAuthMiddleware(t *testing.T) {
 		assert.Equal(t, token, resBody["token"])
 	})
 }
+
+func makeToken(t *testing.T, id string, exp time.Time) string {
+	t.Helper()
+	claimsJSON, err := json.Marshal(map[string]any{"id": id, "type": "auth", "exp": exp.Unix()})
+	assert.NoError(t, err)
+	return "header." + base64.RawURLEncoding.EncodeToString(claimsJSON) + ".signature"
+}
+
+func authCookieFrom(resp *http.Response) *http.Cookie {
+	for _, c := range resp.Cookies() {
+		if c.Name == AuthCookie {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestAuthMiddlewareExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	refreshes := 0
+	refreshStatus := http.StatusOK
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/collections/users/records/user-123" {
+			json.NewEncoder(w).Encode(map[string]any{"id": "user-123", "email": "test@example.com", "name": "Test User"})
+			return
+		}
+		assert.Equal(t, "/api/collections/users/auth-refresh", r.URL.Path)
+		refreshes++
+		if refreshStatus != http.StatusOK {
+			w.WriteHeader(refreshStatus)
+			fmt.Fprintf(w, `{"status": %d, "message": "refresh failed", "data": {}}`, refreshStatus)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"token":  "refreshed-token",
+			"record": map[string]any{"id": "user-123", "email": "test@example.com", "name": "Test User"},
+		})
+	}))
+	defer server.Close()
+
+	app := fiber.New()
+	app.Use(AuthMiddleware(pb.NewClient(server.URL), AuthConfig{
+		RefreshWindow: time.Hour,
+		Now:           func() time.Time { return now },
+	}))
+	app.Get("/protected", func(c fiber.Ctx) error {
+		client := GetPBClient(c)
+		return c.JSON(fiber.Map{
+			"id":    client.GetUserID(),
+			"name":  client.GetCurrentUserName(),
+			"token": client.AuthToken,
+		})
+	})
+
+	request := func(token string, htmx bool) *http.Response {
+		req := httptest.NewRequest(fiber.MethodGet, "/protected", nil)
+		req.AddCookie(&http.Cookie{Name: AuthCookie, Value: token})
+		if htmx {
+			req.Header.Set("HX-Request", "true")
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	t.Run("FreshTokenIsNotRefreshed", func(t *testing.T) {
+		refreshes = 0
+		token := makeToken(t, "user-123", now.Add(48*time.Hour))
+
+		resp := request(token, false)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, 0, refreshes)
+		assert.Nil(t, authCookieFrom(resp))
+	})
+
+	t.Run("ExpiredTokenRedirects", func(t *testing.T) {
+		refreshes = 0
+		token := makeToken(t, "user-123", now.Add(-time.Minute))
+
+		resp := request(token, false)
+
+		assert.Equal(t, fiber.StatusSeeOther, resp.StatusCode)
+		assert.Equal(t, "/login", resp.Header.Get("Location"))
+		assert.Equal(t, 0, refreshes)
+		cookie := authCookieFrom(resp)
+		assert.NotNil(t, cookie)
+		assert.Empty(t, cookie.Value)
+	})
+
+	t.Run("ExpiredTokenHTMXRedirect", func(t *testing.T) {
+		token := makeToken(t, "user-123", now.Add(-time.Minute))
+
+		resp := request(token, true)
+
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, "/login", resp.Header.Get("HX-Redirect"))
+	})
+
+	t.Run("NearExpiryRefreshesAndReissuesCookie", func(t *testing.T) {
+		refreshes = 0
+		token := makeToken(t, "user-123", now.Add(10*time.Minute))
+
+		resp := request(token, false)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, 1, refreshes)
+
+		cookie := authCookieFrom(resp)
+		assert.NotNil(t, cookie)
+		assert.Equal(t, "refreshed-token", cookie.Value)
+		assert.True(t, cookie.HttpOnly)
+
+		var body map[string]string
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, "refreshed-token", body["token"])
+		assert.Equal(t, "Test User", body["name"])
+	})
+
+	t.Run("NearExpiryImpersonationTokenIsNotRefreshed", func(t *testing.T) {
+		// PocketBase rejects refreshing impersonation tokens, so trying would log the user out
+		refreshes = 0
+		refreshStatus = http.StatusForbidden
+		defer func() { refreshStatus = http.StatusOK }()
+		claimsJSON, err := json.Marshal(map[string]any{"id": "user-123", "type": "auth", "exp": now.Add(10 * time.Minute).Unix(), "refreshable": false})
+		assert.NoError(t, err)
+		token := "header." + base64.RawURLEncoding.EncodeToString(claimsJSON) + ".signature"
+
+		resp := request(token, true)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, 0, refreshes)
+		assert.Nil(t, authCookieFrom(resp))
+		var body map[string]string
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, token, body["token"])
+		assert.Equal(t, "Test User", body["name"])
+	})
+
+	t.Run("RejectedRefreshRedirects", func(t *testing.T) {
+		refreshStatus = http.StatusUnauthorized
+		defer func() { refreshStatus = http.StatusOK }()
+		token := makeToken(t, "user-123", now.Add(10*time.Minute))
+
+		resp := request(token, true)
+
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, "/login", resp.Header.Get("HX-Redirect"))
+	})
+
+	t.Run("TransientRefreshFailureKeepsToken", func(t *testing.T) {
+		refreshStatus = http.StatusInternalServerError
+		defer func() { refreshStatus = http.StatusOK }()
+		token := makeToken(t, "user-123", now.Add(10*time.Minute))
+
+		resp := request(token, false)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body map[string]string
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, token, body["token"])
+		assert.Equal(t, "user-123", body["id"])
+	})
+}
+
+func TestAuthMiddlewareUserRecord(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	lookups := 0
+	lookupStatus := http.StatusOK
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/collections/users/records/user-123", r.URL.Path)
+		lookups++
+		if lookupStatus != http.StatusOK {
+			w.WriteHeader(lookupStatus)
+			fmt.Fprintf(w, `{"status": %d, "message": "lookup failed", "data": {}}`, lookupStatus)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":           "user-123",
+			"collectionId": "_pb_users_auth_",
+			"email":        "test@example.com",
+			"name":         "Test User",
+			"avatar":       "face_abc.png",
+			"verified":     true,
+		})
+	}))
+	defer server.Close()
+
+	users := NewUserCache(time.Minute)
+	app := fiber.New()
+	app.Use(AuthMiddleware(pb.NewClient(server.URL), AuthConfig{
+		Users: users,
+		Now:   func() time.Time { return now },
+	}))
+	app.Get("/protected", func(c fiber.Ctx) error {
+		client := GetPBClient(c)
+		return c.JSON(fiber.Map{
+			"name":     client.GetCurrentUserName(),
+			"email":    client.GetCurrentUserEmail(),
+			"avatar":   client.GetCurrentUserAvatarURL(),
+			"verified": client.AuthRecord.Verified,
+		})
+	})
+
+	request := func(token string) *http.Response {
+		req := httptest.NewRequest(fiber.MethodGet, "/protected", nil)
+		req.AddCookie(&http.Cookie{Name: AuthCookie, Value: token})
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	t.Run("PopulatesAndCachesRecord", func(t *testing.T) {
+		lookups = 0
+		token := makeToken(t, "user-123", now.Add(48*time.Hour))
+
+		resp := request(token)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var body map[string]any
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, "Test User", body["name"])
+		assert.Equal(t, "test@example.com", body["email"])
+		assert.Equal(t, server.URL+"/api/files/_pb_users_auth_/user-123/face_abc.png", body["avatar"])
+		assert.Equal(t, true, body["verified"])
+
+		request(token)
+		assert.Equal(t, 1, lookups)
+	})
+
+	t.Run("CacheExpires", func(t *testing.T) {
+		lookups = 0
+		token := makeToken(t, "user-123", now.Add(47*time.Hour))
+
+		request(token)
+		now = now.Add(2 * time.Minute)
+		request(token)
+
+		assert.Equal(t, 2, lookups)
+	})
+
+	t.Run("ForgetReloadsRecord", func(t *testing.T) {
+		lookups = 0
+		token := makeToken(t, "user-123", now.Add(44*time.Hour))
+		other := makeToken(t, "user-123", now.Add(43*time.Hour))
+
+		request(token)
+		request(other)
+		users.Forget("user-123")
+		request(token)
+		request(other)
+
+		assert.Equal(t, 4, lookups, "every token of the user is dropped")
+	})
+
+	t.Run("DeletedUserRedirects", func(t *testing.T) {
+		lookupStatus = http.StatusNotFound
+		defer func() { lookupStatus = http.StatusOK }()
+		token := makeToken(t, "user-123", now.Add(46*time.Hour))
+
+		resp := request(token)
+
+		assert.Equal(t, fiber.StatusSeeOther, resp.StatusCode)
+		assert.Equal(t, "/login", resp.Header.Get("Location"))
+	})
+
+	t.Run("TransientFailureFallsBackToTokenID", func(t *testing.T) {
+		lookupStatus = http.StatusInternalServerError
+		defer func() { lookupStatus = http.StatusOK }()
+		token := makeToken(t, "user-123", now.Add(45*time.Hour))
+
+		resp := request(token)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body map[string]any
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, "", body["email"])
+		assert.Equal(t, false, body["verified"])
+	})
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+
+	"github.com/torresposso/gosmic/pb"
+)
+
+// PropagateRequestID carries the ID assigned by the requestid middleware into
+// c.Context(), so PocketBase calls made for the request send the same X-Request-ID.
+// Register it after requestid.New() and RequestTimeout.
+func PropagateRequestID() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		id := requestid.FromContext(c)
+		if id == "" {
+			return c.Next()
+		}
+
+		parent := c.Context()
+		// The ID may come from a request header backed by a reused buffer
+		c.SetContext(pb.ContextWithRequestID(parent, strings.Clone(id)))
+		defer c.SetContext(parent)
+
+		return c.Next()
+	}
+}
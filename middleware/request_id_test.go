@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/torresposso/gosmic/pb"
+)
+
+func TestPropagateRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(requestid.New())
+	app.Use(PropagateRequestID())
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString(pb.RequestIDFromContext(c.Context()))
+	})
+
+	t.Run("ReusesIncomingID", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set("X-Request-ID", "abc-123")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "abc-123", getResponseBody(resp))
+	})
+
+	t.Run("GeneratesID", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+		assert.NoError(t, err)
+		id := getResponseBody(resp)
+		assert.NotEmpty(t, id)
+		assert.Equal(t, resp.Header.Get("X-Request-ID"), id)
+	})
+}
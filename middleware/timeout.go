@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RequestTimeout derives a cancellable context for every request and exposes it via c.Context().
+// The context expires after the given timeout and is also cancelled when the server shuts down,
+// so in-flight PocketBase calls made with c.Context() are aborted instead of outliving the request.
+func RequestTimeout(timeout time.Duration) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		parent := c.Context()
+
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+
+		// fasthttp closes the RequestCtx's Done channel on server shutdown
+		stop := context.AfterFunc(c.RequestCtx(), cancel)
+		defer stop()
+
+		c.SetContext(ctx)
+		defer c.SetContext(parent)
+
+		return c.Next()
+	}
+}
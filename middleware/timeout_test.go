@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestTimeout(50 * time.Millisecond))
+
+	app.Get("/deadline", func(c fiber.Ctx) error {
+		_, ok := c.Context().Deadline()
+		if !ok {
+			return c.SendString("none")
+		}
+		return c.SendString("set")
+	})
+
+	app.Get("/slow", func(c fiber.Ctx) error {
+		select {
+		case <-c.Context().Done():
+			return c.SendString(c.Context().Err().Error())
+		case <-time.After(time.Second):
+			return c.SendString("finished")
+		}
+	})
+
+	t.Run("ContextHasDeadline", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/deadline", nil)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "set", getResponseBody(resp))
+	})
+
+	t.Run("ContextCancelledAfterTimeout", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/slow", nil)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "context deadline exceeded", getResponseBody(resp))
+	})
+}
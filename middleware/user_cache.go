@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/torresposso/gosmic/pb"
+)
+
+// UserCache keeps recently fetched auth records for a short time so
+// AuthMiddleware does not hit PocketBase for the user on every request.
+//
+// Entries are keyed by token rather than user ID: a record is only ever
+// served for a token PocketBase has already accepted.
+type UserCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]userCacheEntry
+}
+
+type userCacheEntry struct {
+	user    pb.User
+	expires time.Time
+}
+
+// NewUserCache returns an empty cache keeping records for ttl. Pass it to
+// several AuthMiddleware instances through AuthConfig.Users to share it.
+func NewUserCache(ttl time.Duration) *UserCache {
+	return &UserCache{ttl: ttl, entries: make(map[string]userCacheEntry)}
+}
+
+// Forget drops every cached record of the user, for all of their tokens, so
+// the next request loads it again. Call it after changing the user in a way
+// handlers depend on, such as verifying their email.
+func (uc *UserCache) Forget(userID string) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	for key, entry := range uc.entries {
+		if entry.user.ID == userID {
+			delete(uc.entries, key)
+		}
+	}
+}
+
+// get returns a copy of the cached record for token, if still fresh.
+func (uc *UserCache) get(token string, now time.Time) (*pb.User, bool) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	entry, ok := uc.entries[token]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(entry.expires) {
+		delete(uc.entries, token)
+		return nil, false
+	}
+	user := entry.user
+	return &user, true
+}
+
+// set stores a copy of user for token and drops any expired entries.
+func (uc *UserCache) set(token string, user *pb.User, now time.Time) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	for key, entry := range uc.entries {
+		if !now.Before(entry.expires) {
+			delete(uc.entries, key)
+		}
+	}
+	// Fiber reuses the request buffers backing token, so the key needs its own copy
+	uc.entries[strings.Clone(token)] = userCacheEntry{user: *user, expires: now.Add(uc.ttl)}
+}
@@ -0,0 +1,127 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/torresposso/gosmic/pb"
+)
+
+// All is the app's schema history. Append new migrations with the next
+// version; never change one that has shipped.
+var All = []Migration{
+	{Version: 1, Name: "users profile fields and rules", Up: usersV1},
+	{Version: 2, Name: "posts collection", Up: postsV1},
+	{Version: 3, Name: "webauthn_credentials collection", Up: webauthnCredentialsV1},
+	{Version: 4, Name: "posts publishing requires a verified email", Up: postsV2},
+}
+
+const ownUser = "id = @request.auth.id"
+
+// usersV1 keeps PocketBase's default users collection, making sure it has the
+// profile fields the app shows and that users only see their own record.
+func usersV1(ctx context.Context, client *pb.Client) error {
+	return EnsureCollection(ctx, client, pb.CollectionSchema{
+		Name: "users",
+		Type: "auth",
+		Fields: []pb.SchemaField{
+			{Name: "name", Type: "text", Options: map[string]any{"max": 255}},
+			{Name: "avatar", Type: "file", Options: map[string]any{
+				"maxSelect": 1,
+				"maxSize":   5 << 20,
+				"mimeTypes": []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+			}},
+		},
+		ListRule:   pb.Rule(ownUser),
+		ViewRule:   pb.Rule(ownUser),
+		CreateRule: pb.Rule(""), // Anyone may register
+		UpdateRule: pb.Rule(ownUser),
+		DeleteRule: pb.Rule(ownUser),
+	})
+}
+
+// postsV1 creates the mission logs: public ones are visible to everyone,
+// private ones to their author only, and authors cannot hand posts over.
+func postsV1(ctx context.Context, client *pb.Client) error {
+	users, err := client.CollectionSchema(ctx, "users")
+	if err != nil {
+		return err
+	}
+
+	const author = "author = @request.auth.id"
+	return EnsureCollection(ctx, client, pb.CollectionSchema{
+		Name: "posts",
+		Type: "base",
+		Fields: []pb.SchemaField{
+			{Name: "title", Type: "text", Required: true, Options: map[string]any{"max": 200}},
+			{Name: "content", Type: "text", Options: map[string]any{"max": 20000}},
+			{Name: "author", Type: "relation", Required: true, Options: map[string]any{
+				"collectionId":  users.ID,
+				"maxSelect":     1,
+				"cascadeDelete": true,
+			}},
+			{Name: "public", Type: "bool"},
+			{Name: "created", Type: "autodate", Options: map[string]any{"onCreate": true, "onUpdate": false}},
+			{Name: "updated", Type: "autodate", Options: map[string]any{"onCreate": true, "onUpdate": true}},
+		},
+		Indexes: []string{
+			"CREATE INDEX `idx_posts_author` ON `posts` (`author`)",
+			"CREATE INDEX `idx_posts_public_created` ON `posts` (`public`, `created`)",
+		},
+		ListRule:   pb.Rule("public = true || " + author),
+		ViewRule:   pb.Rule("public = true || " + author),
+		CreateRule: pb.Rule(`@request.auth.id != "" && @request.body.author = @request.auth.id`),
+		UpdateRule: pb.Rule(author + " && (@request.body.author:isset = false || @request.body.author = @request.auth.id)"),
+		DeleteRule: pb.Rule(author),
+	})
+}
+
+// postsV2 reserves public posts to users who confirmed their email, so the
+// verification policy holds for clients that talk to PocketBase directly.
+// Unverified users keep writing private posts and may still unpublish.
+func postsV2(ctx context.Context, client *pb.Client) error {
+	const (
+		author     = "author = @request.auth.id"
+		canPublish = "(@request.body.public != true || @request.auth.verified = true)"
+	)
+	return EnsureCollection(ctx, client, pb.CollectionSchema{
+		Name:       "posts",
+		Type:       "base",
+		ListRule:   pb.Rule("public = true || " + author),
+		ViewRule:   pb.Rule("public = true || " + author),
+		CreateRule: pb.Rule(`@request.auth.id != "" && @request.body.author = @request.auth.id && ` + canPublish),
+		UpdateRule: pb.Rule(author + " && (@request.body.author:isset = false || @request.body.author = @request.auth.id) && " + canPublish),
+		DeleteRule: pb.Rule(author),
+	})
+}
+
+// webauthnCredentialsV1 stores passkeys. Updates and deletes are reserved to
+// superusers so sign counts cannot be rolled back by their owner.
+func webauthnCredentialsV1(ctx context.Context, client *pb.Client) error {
+	users, err := client.CollectionSchema(ctx, "users")
+	if err != nil {
+		return err
+	}
+
+	const owner = "user = @request.auth.id"
+	return EnsureCollection(ctx, client, pb.CollectionSchema{
+		Name: "webauthn_credentials",
+		Type: "base",
+		Fields: []pb.SchemaField{
+			{Name: "user", Type: "relation", Required: true, Options: map[string]any{
+				"collectionId":  users.ID,
+				"maxSelect":     1,
+				"cascadeDelete": true,
+			}},
+			{Name: "credentialId", Type: "text", Required: true},
+			{Name: "name", Type: "text", Options: map[string]any{"max": 100}},
+			{Name: "credential", Type: "json", Required: true},
+			{Name: "created", Type: "autodate", Options: map[string]any{"onCreate": true, "onUpdate": false}},
+		},
+		Indexes: []string{
+			"CREATE UNIQUE INDEX `idx_webauthn_credentials_credentialId` ON `webauthn_credentials` (`credentialId`)",
+		},
+		ListRule:   pb.Rule(owner),
+		ViewRule:   pb.Rule(owner),
+		CreateRule: pb.Rule(owner),
+	})
+}
@@ -0,0 +1,215 @@
+// Package migrations bootstraps and evolves the PocketBase schema the app
+// relies on. Migrations run with a superuser client, are applied in version
+// order and recorded in the app_migrations collection so each runs once.
+package migrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/torresposso/gosmic/pb"
+)
+
+// Migration is one versioned schema change. Up must be idempotent: if the app
+// stops between Up and recording the version, Up runs again next time.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, client *pb.Client) error
+}
+
+// historyCollection records applied migration versions.
+const historyCollection = "app_migrations"
+
+type appliedMigration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+}
+
+var historySchema = pb.CollectionSchema{
+	Name: historyCollection,
+	Type: "base",
+	Fields: []pb.SchemaField{
+		{Name: "version", Type: "number", Required: true, Options: map[string]any{"onlyInt": true}},
+		{Name: "name", Type: "text"},
+		{Name: "created", Type: "autodate", Options: map[string]any{"onCreate": true, "onUpdate": false}},
+	},
+	Indexes: []string{"CREATE UNIQUE INDEX `idx_app_migrations_version` ON `app_migrations` (`version`)"},
+	// Nil rules: only superusers may read or write the history
+}
+
+// Run applies the migrations PocketBase has not recorded yet, in version
+// order, and returns the ones it applied. client must be a superuser client.
+func Run(ctx context.Context, client *pb.Client, migrations []Migration) ([]Migration, error) {
+	pending, err := Pending(ctx, client, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	history := pb.NewCollection[appliedMigration](client, historyCollection)
+	var applied []Migration
+	for _, m := range pending {
+		if err := m.Up(ctx, client); err != nil {
+			return applied, fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		if _, err := history.Create(ctx, appliedMigration{Version: m.Version, Name: m.Name}); err != nil {
+			return applied, fmt.Errorf("recording migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// Pending returns the migrations not applied yet, sorted by version. It
+// creates the history collection on first use.
+func Pending(ctx context.Context, client *pb.Client, migrations []Migration) ([]Migration, error) {
+	if err := EnsureCollection(ctx, client, historySchema); err != nil {
+		return nil, fmt.Errorf("preparing %s: %w", historyCollection, err)
+	}
+
+	history, err := pb.NewCollection[appliedMigration](client, historyCollection).FullList(ctx, pb.ListOptions{Sort: "version"})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", historyCollection, err)
+	}
+	done := make(map[int]bool, len(history))
+	for _, h := range history {
+		done[h.Version] = true
+	}
+
+	sorted := slices.SortedFunc(slices.Values(migrations), func(a, b Migration) int { return a.Version - b.Version })
+	var pending []Migration
+	for i, m := range sorted {
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+		if !done[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// EnsureCollection creates the collection described by want, or brings an
+// existing one in line with it: missing fields and indexes are added, the
+// settings of listed fields and all five rules are set to want's. Fields and
+// indexes want does not mention are kept, so it is safe on collections
+// PocketBase or an admin extended. Nothing is sent when the schema already matches.
+func EnsureCollection(ctx context.Context, client *pb.Client, want pb.CollectionSchema) error {
+	existing, err := client.CollectionSchema(ctx, want.Name)
+	if errors.Is(err, pb.ErrNotFound) {
+		_, err = client.CreateCollectionSchema(ctx, want)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	merged, changed, err := merge(*existing, want)
+	if err != nil || !changed {
+		return err
+	}
+	_, err = client.UpdateCollectionSchema(ctx, existing.ID, merged)
+	return err
+}
+
+// merge returns existing updated with want and whether anything changed.
+func merge(existing, want pb.CollectionSchema) (pb.CollectionSchema, bool, error) {
+	merged := existing
+	merged.Fields = slices.Clone(existing.Fields)
+	merged.Indexes = slices.Clone(existing.Indexes)
+	changed := false
+
+	for _, field := range want.Fields {
+		current := merged.Field(field.Name)
+		if current == nil {
+			merged.Fields = append(merged.Fields, field)
+			changed = true
+			continue
+		}
+		if current.Type != field.Type {
+			return existing, false, fmt.Errorf("field %s.%s is %s, want %s", want.Name, field.Name, current.Type, field.Type)
+		}
+
+		options := maps.Clone(current.Options)
+		for key, value := range normalize(field.Options) {
+			if options == nil {
+				options = map[string]any{}
+			}
+			options[key] = value
+		}
+		if current.Required != field.Required || current.Hidden != field.Hidden || !reflect.DeepEqual(options, current.Options) {
+			current.Required, current.Hidden, current.Options = field.Required, field.Hidden, options
+			changed = true
+		}
+	}
+
+	for _, index := range want.Indexes {
+		i := slices.IndexFunc(merged.Indexes, func(existing string) bool { return indexName(existing) == indexName(index) })
+		switch {
+		case i < 0:
+			merged.Indexes = append(merged.Indexes, index)
+			changed = true
+		case merged.Indexes[i] != index:
+			merged.Indexes[i] = index
+			changed = true
+		}
+	}
+
+	for _, rule := range []struct {
+		current **string
+		want    *string
+	}{
+		{&merged.ListRule, want.ListRule},
+		{&merged.ViewRule, want.ViewRule},
+		{&merged.CreateRule, want.CreateRule},
+		{&merged.UpdateRule, want.UpdateRule},
+		{&merged.DeleteRule, want.DeleteRule},
+	} {
+		if !sameRule(*rule.current, rule.want) {
+			*rule.current = rule.want
+			changed = true
+		}
+	}
+
+	return merged, changed, nil
+}
+
+// normalize gives options the shape they have after a JSON round trip, so
+// numbers compare equal to what PocketBase returns.
+func normalize(options map[string]any) map[string]any {
+	if len(options) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(options)
+	var out map[string]any
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func sameRule(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// indexName extracts the name from a CREATE [UNIQUE] INDEX [IF NOT EXISTS]
+// statement.
+func indexName(index string) string {
+	words := strings.Fields(index)
+	i := slices.IndexFunc(words, func(word string) bool { return strings.EqualFold(word, "INDEX") })
+	if i < 0 || i+1 == len(words) {
+		return index
+	}
+	rest := words[i+1:]
+	if len(rest) > 3 && strings.EqualFold(rest[0], "IF") && strings.EqualFold(rest[1], "NOT") && strings.EqualFold(rest[2], "EXISTS") {
+		rest = rest[3:]
+	}
+	return strings.Trim(rest[0], "`\"")
+}
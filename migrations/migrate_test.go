@@ -0,0 +1,277 @@
+package migrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/pb"
+)
+
+// fakeAdmin emulates PocketBase's collection management API for a superuser.
+type fakeAdmin struct {
+	mu          sync.Mutex
+	collections map[string]pb.CollectionSchema
+	history     []map[string]any
+	writes      int // Collection creates and updates
+}
+
+func newFakeAdmin(t *testing.T) (*fakeAdmin, *pb.Client) {
+	f := &fakeAdmin{collections: map[string]pb.CollectionSchema{}}
+	f.put(defaultUsers())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/collections/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if schema, ok := f.find(r.PathValue("name")); ok {
+			json.NewEncoder(w).Encode(schema)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status":404,"message":"The requested resource wasn't found.","data":{}}`))
+	})
+	mux.HandleFunc("POST /api/collections", func(w http.ResponseWriter, r *http.Request) {
+		var schema pb.CollectionSchema
+		json.NewDecoder(r.Body).Decode(&schema)
+		schema.ID = "pbc_" + schema.Name
+		schema.Fields = append([]pb.SchemaField{{ID: "text3208210256", Name: "id", Type: "text", System: true, Required: true}}, schema.Fields...)
+		f.put(schema)
+		json.NewEncoder(w).Encode(schema)
+	})
+	mux.HandleFunc("PATCH /api/collections/{id}", func(w http.ResponseWriter, r *http.Request) {
+		schema, ok := f.find(r.PathValue("id"))
+		require.True(t, ok)
+		json.NewDecoder(r.Body).Decode(&schema)
+		f.put(schema)
+		json.NewEncoder(w).Encode(schema)
+	})
+	mux.HandleFunc("GET /api/collections/app_migrations/records", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{"page": 1, "perPage": 500, "totalItems": len(f.history), "totalPages": 1, "items": f.history})
+	})
+	mux.HandleFunc("POST /api/collections/app_migrations/records", func(w http.ResponseWriter, r *http.Request) {
+		var record map[string]any
+		json.NewDecoder(r.Body).Decode(&record)
+		f.mu.Lock()
+		f.history = append(f.history, record)
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(record)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer superuser-token", r.Header.Get("Authorization"))
+		if r.Method != http.MethodGet && r.URL.Path != "/api/collections/app_migrations/records" {
+			f.mu.Lock()
+			f.writes++
+			f.mu.Unlock()
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return f, pb.NewClient(server.URL).WithToken("superuser-token")
+}
+
+func (f *fakeAdmin) put(schema pb.CollectionSchema) {
+	// Round trip through JSON like PocketBase would, so options hold float64s
+	data, _ := json.Marshal(schema)
+	var stored pb.CollectionSchema
+	json.Unmarshal(data, &stored)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.collections[stored.Name] = stored
+}
+
+func (f *fakeAdmin) find(nameOrID string) (pb.CollectionSchema, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, schema := range f.collections {
+		if schema.Name == nameOrID || schema.ID == nameOrID {
+			return schema, true
+		}
+	}
+	return pb.CollectionSchema{}, false
+}
+
+// defaultUsers is the users collection of a fresh PocketBase.
+func defaultUsers() pb.CollectionSchema {
+	return pb.CollectionSchema{
+		ID:   "_pb_users_auth_",
+		Name: "users",
+		Type: "auth",
+		Fields: []pb.SchemaField{
+			{ID: "text3208210256", Name: "id", Type: "text", System: true, Required: true},
+			{ID: "password901924565", Name: "password", Type: "password", System: true, Hidden: true, Required: true, Options: map[string]any{"min": 8}},
+			{ID: "email3885137012", Name: "email", Type: "email", System: true, Required: true},
+			{ID: "bool256245529", Name: "verified", Type: "bool", System: true},
+			{ID: "text1579384326", Name: "name", Type: "text", Options: map[string]any{"max": 255}},
+			{ID: "file376926767", Name: "avatar", Type: "file", Options: map[string]any{"maxSelect": 1, "maxSize": 0, "protected": false}},
+		},
+		Indexes:    []string{"CREATE UNIQUE INDEX `idx_email__pb_users_auth_` ON `users` (`email`) WHERE `email` != ''"},
+		ListRule:   pb.Rule("id = @request.auth.id"),
+		ViewRule:   pb.Rule("id = @request.auth.id"),
+		CreateRule: pb.Rule(""),
+		UpdateRule: pb.Rule("id = @request.auth.id"),
+		DeleteRule: pb.Rule("id = @request.auth.id"),
+	}
+}
+
+func TestRun(t *testing.T) {
+	f, client := newFakeAdmin(t)
+	ctx := context.Background()
+
+	applied, err := Run(ctx, client, All)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(All))
+	assert.Len(t, f.history, len(All))
+
+	posts, ok := f.find("posts")
+	require.True(t, ok)
+	assert.Equal(t, "_pb_users_auth_", posts.Field("author").Options["collectionId"])
+	assert.True(t, posts.Field("title").Required)
+	assert.Equal(t, "public = true || author = @request.auth.id", *posts.ListRule)
+	assert.Contains(t, *posts.CreateRule, "@request.auth.verified = true", "only verified users publish")
+	assert.Contains(t, *posts.UpdateRule, "@request.auth.verified = true")
+	assert.Len(t, posts.Indexes, 2)
+
+	users, _ := f.find("users")
+	assert.NotNil(t, users.Field("password"), "system fields survive the update")
+	assert.Equal(t, "file376926767", users.Field("avatar").ID)
+	assert.Equal(t, false, users.Field("avatar").Options["protected"], "unmanaged options are kept")
+	assert.Contains(t, users.Field("avatar").Options["mimeTypes"], "image/png")
+
+	_, ok = f.find("webauthn_credentials")
+	assert.True(t, ok)
+
+	t.Run("SecondRunIsANoOp", func(t *testing.T) {
+		writes := f.writes
+		applied, err := Run(ctx, client, All)
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+		assert.Equal(t, writes, f.writes)
+	})
+
+	t.Run("ReappliedMigrationsChangeNothing", func(t *testing.T) {
+		// Later migrations may supersede earlier ones, so each is rerun
+		// right after itself on a fresh PocketBase
+		f, client := newFakeAdmin(t)
+		for _, m := range All {
+			require.NoError(t, m.Up(ctx, client))
+			writes := f.writes
+			require.NoError(t, m.Up(ctx, client))
+			assert.Equal(t, writes, f.writes, "migration %d", m.Version)
+		}
+	})
+}
+
+func TestRunStopsAtFailure(t *testing.T) {
+	f, client := newFakeAdmin(t)
+	ctx := context.Background()
+
+	var ran []int
+	step := func(version int, err error) Migration {
+		return Migration{Version: version, Name: "step", Up: func(context.Context, *pb.Client) error {
+			ran = append(ran, version)
+			return err
+		}}
+	}
+	boom := errors.New("boom")
+
+	applied, err := Run(ctx, client, []Migration{step(2, boom), step(1, nil), step(3, nil)})
+	assert.ErrorIs(t, err, boom)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, []int{1, 2}, ran)
+	assert.Len(t, f.history, 1)
+
+	ran = nil
+	applied, err = Run(ctx, client, []Migration{step(1, nil), step(2, nil), step(3, nil)})
+	require.NoError(t, err)
+	assert.Len(t, applied, 2)
+	assert.Equal(t, []int{2, 3}, ran, "applied versions are not run again")
+
+	_, err = Pending(ctx, client, []Migration{step(4, nil), step(4, nil)})
+	assert.ErrorContains(t, err, "duplicate migration version 4")
+}
+
+func TestEnsureCollection(t *testing.T) {
+	f, client := newFakeAdmin(t)
+	ctx := context.Background()
+	f.put(pb.CollectionSchema{
+		ID:   "pbc_posts",
+		Name: "posts",
+		Type: "base",
+		Fields: []pb.SchemaField{
+			{ID: "text1", Name: "title", Type: "text"},
+			{ID: "text2", Name: "mood", Type: "text"},
+		},
+		Indexes: []string{"CREATE INDEX `idx_posts_title` ON `posts` (`title`, `mood`)"},
+	})
+
+	want := pb.CollectionSchema{
+		Name: "posts",
+		Type: "base",
+		Fields: []pb.SchemaField{
+			{Name: "title", Type: "text", Required: true, Options: map[string]any{"max": 200}},
+			{Name: "public", Type: "bool"},
+		},
+		Indexes:  []string{"CREATE INDEX `idx_posts_title` ON `posts` (`title`)"},
+		ListRule: pb.Rule("public = true"),
+	}
+	require.NoError(t, EnsureCollection(ctx, client, want))
+
+	posts, _ := f.find("posts")
+	assert.Equal(t, []string{"title", "mood", "public"}, fieldNames(posts))
+	assert.Equal(t, "text1", posts.Field("title").ID)
+	assert.True(t, posts.Field("title").Required)
+	assert.Equal(t, float64(200), posts.Field("title").Options["max"])
+	assert.Equal(t, []string{"CREATE INDEX `idx_posts_title` ON `posts` (`title`)"}, posts.Indexes)
+	assert.Equal(t, "public = true", *posts.ListRule)
+	assert.Nil(t, posts.DeleteRule)
+
+	writes := f.writes
+	require.NoError(t, EnsureCollection(ctx, client, want))
+	assert.Equal(t, writes, f.writes, "a matching schema is not rewritten")
+
+	want.Fields = []pb.SchemaField{{Name: "mood", Type: "number"}}
+	assert.ErrorContains(t, EnsureCollection(ctx, client, want), "field posts.mood is text, want number")
+}
+
+func TestIndexName(t *testing.T) {
+	assert.Equal(t, "idx_posts_author", indexName("CREATE INDEX `idx_posts_author` ON `posts` (`author`)"))
+	assert.Equal(t, "idx_email", indexName("create unique index idx_email ON users (email)"))
+	assert.Equal(t, "idx_posts_author", indexName("CREATE INDEX IF NOT EXISTS `idx_posts_author` ON `posts` (`author`)"))
+	assert.Equal(t, "idx_email", indexName("create unique index if  not\texists \"idx_email\" on users (email)"))
+	assert.Equal(t, "if_stale", indexName("CREATE INDEX if_stale ON posts (updated)"), "only the whole phrase is skipped")
+}
+
+func TestEnsureCollectionMatchesIfNotExistsIndexes(t *testing.T) {
+	f, client := newFakeAdmin(t)
+	ctx := context.Background()
+	schema := pb.CollectionSchema{
+		Name:    "logs",
+		Type:    "base",
+		Indexes: []string{"CREATE INDEX IF NOT EXISTS `idx_logs_created` ON `logs` (`created`)"},
+	}
+	require.NoError(t, EnsureCollection(ctx, client, schema))
+
+	// The same index written without IF NOT EXISTS replaces it instead of
+	// being added a second time under the same name
+	schema.Indexes = []string{"CREATE INDEX `idx_logs_created` ON `logs` (`created`)"}
+	require.NoError(t, EnsureCollection(ctx, client, schema))
+	logs, _ := f.find("logs")
+	assert.Equal(t, schema.Indexes, logs.Indexes)
+}
+
+func fieldNames(schema pb.CollectionSchema) []string {
+	var names []string
+	for _, field := range schema.Fields {
+		names = append(names, field.Name)
+	}
+	return names
+}
@@ -0,0 +1,157 @@
+package pb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Batch queues record writes and sends them to PocketBase's /api/batch in a
+// single transaction: either every request succeeds or none is applied.
+// The Batch API must be enabled in the PocketBase settings.
+type Batch struct {
+	client   *Client
+	requests []batchRequest
+}
+
+type batchRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   any    `json:"body,omitempty"`
+}
+
+// BatchResult is the response to one request of a batch, in queue order.
+type BatchResult struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// Decode unmarshals the result's record into out. Results of deletes have no body.
+func (r BatchResult) Decode(out any) error {
+	if len(r.Body) == 0 || string(r.Body) == "null" {
+		return nil
+	}
+	return json.Unmarshal(r.Body, out)
+}
+
+// BatchError reports the request that made PocketBase roll a batch back.
+// It unwraps to that request's *APIError, so errors.Is and FieldErrors work
+// as they do for a single call.
+type BatchError struct {
+	Index int // Position of the failing request in the batch
+	Err   *APIError
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch request %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Batch starts an empty batch sent with the client's token.
+func (c *Client) Batch() *Batch {
+	return &Batch{client: c}
+}
+
+func batchRecordsURL(collection string) string {
+	return "/api/collections/" + url.PathEscape(collection) + "/records"
+}
+
+// Create queues the creation of a record built from data (a struct or map).
+func (b *Batch) Create(collection string, data any) *Batch {
+	b.requests = append(b.requests, batchRequest{Method: http.MethodPost, URL: batchRecordsURL(collection), Body: data})
+	return b
+}
+
+// Update queues a patch of the record with the given id.
+func (b *Batch) Update(collection, id string, data any) *Batch {
+	b.requests = append(b.requests, batchRequest{Method: http.MethodPatch, URL: batchRecordsURL(collection) + "/" + url.PathEscape(id), Body: data})
+	return b
+}
+
+// Upsert queues an update of the record whose id is in data, or its creation
+// when no such record exists.
+func (b *Batch) Upsert(collection string, data any) *Batch {
+	b.requests = append(b.requests, batchRequest{Method: http.MethodPut, URL: batchRecordsURL(collection), Body: data})
+	return b
+}
+
+// Delete queues the removal of the record with the given id.
+func (b *Batch) Delete(collection, id string) *Batch {
+	b.requests = append(b.requests, batchRequest{Method: http.MethodDelete, URL: batchRecordsURL(collection) + "/" + url.PathEscape(id)})
+	return b
+}
+
+// Len returns the number of queued requests.
+func (b *Batch) Len() int {
+	return len(b.requests)
+}
+
+// Send runs the queued requests in one transaction and returns their results
+// in order. When PocketBase rejects one of them nothing is applied and the
+// error is a *BatchError. An empty batch sends nothing.
+func (b *Batch) Send(ctx context.Context) ([]BatchResult, error) {
+	if len(b.requests) == 0 {
+		return nil, nil
+	}
+
+	req, err := b.client.newRequest(ctx, http.MethodPost, "/api/batch", map[string]any{"requests": b.requests})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newBatchError(resp)
+	}
+
+	var results []BatchResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return results, nil
+}
+
+// newBatchError extracts the failing request from PocketBase's
+// "Batch transaction failed." envelope, whose data.requests maps the index of
+// the rejected request to its own error response. Other failures (batch
+// disabled, too many requests...) are plain APIErrors.
+func newBatchError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+
+	var envelope struct {
+		Data struct {
+			Requests map[string]struct {
+				Response *APIError `json:"response"`
+			} `json:"requests"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(body, &envelope) == nil {
+		for key, failed := range envelope.Data.Requests {
+			index, err := strconv.Atoi(key)
+			if err != nil || failed.Response == nil {
+				continue
+			}
+			if failed.Response.Status == 0 {
+				failed.Response.Status = resp.StatusCode
+			}
+			return &BatchError{Index: index, Err: failed.Response}
+		}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return fmt.Errorf("failed to send batch: %w", newAPIError(resp))
+}
@@ -0,0 +1,108 @@
+package pb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SendsRequestsInOneCall", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			assert.Equal(t, "/api/batch", r.URL.Path)
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "Bearer user-token", r.Header.Get("Authorization"))
+
+			var body struct {
+				Requests []map[string]any `json:"requests"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, []map[string]any{
+				{"method": "POST", "url": "/api/collections/tasks/records", "body": map[string]any{"name": "Calibrate"}},
+				{"method": "PATCH", "url": "/api/collections/tasks/records/t1", "body": map[string]any{"done": true}},
+				{"method": "PUT", "url": "/api/collections/tasks/records", "body": map[string]any{"id": "t9", "name": "Refuel", "done": false}},
+				{"method": "DELETE", "url": "/api/collections/tasks/records/t2"},
+			}, body.Requests)
+
+			json.NewEncoder(w).Encode([]map[string]any{
+				{"status": 200, "body": map[string]any{"id": "t3", "name": "Calibrate"}},
+				{"status": 200, "body": map[string]any{"id": "t1", "done": true}},
+				{"status": 200, "body": map[string]any{"id": "t9", "name": "Refuel"}},
+				{"status": 204, "body": nil},
+			})
+		}))
+		defer server.Close()
+
+		batch := NewClient(server.URL).WithToken("user-token").Batch().
+			Create("tasks", map[string]any{"name": "Calibrate"}).
+			Update("tasks", "t1", map[string]any{"done": true}).
+			Upsert("tasks", testTask{ID: "t9", Name: "Refuel"}).
+			Delete("tasks", "t2")
+		assert.Equal(t, 4, batch.Len())
+
+		results, err := batch.Send(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		require.Len(t, results, 4)
+
+		var created testTask
+		require.NoError(t, results[0].Decode(&created))
+		assert.Equal(t, testTask{ID: "t3", Name: "Calibrate"}, created)
+
+		var deleted testTask
+		assert.Equal(t, http.StatusNoContent, results[3].Status)
+		assert.NoError(t, results[3].Decode(&deleted))
+	})
+
+	t.Run("ReportsTheFailingRequest", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"message":"Batch transaction failed.","data":{"requests":{"1":{
+				"code":"batch_request_failed","message":"Batch request failed.",
+				"response":{"status":400,"message":"Failed to create record.","data":{"name":{"code":"validation_required","message":"Cannot be blank."}}}
+			}}}}`))
+		}))
+		defer server.Close()
+
+		_, err := NewClient(server.URL).Batch().
+			Create("tasks", map[string]any{"name": "Calibrate"}).
+			Create("tasks", map[string]any{"name": ""}).
+			Send(ctx)
+
+		var batchErr *BatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, 1, batchErr.Index)
+		assert.ErrorIs(t, err, ErrBadRequest)
+		assert.Equal(t, map[string]string{"name": "Cannot be blank."}, FieldErrors(err))
+		assert.Contains(t, err.Error(), "batch request 1: pocketbase: 400 Failed to create record.")
+	})
+
+	t.Run("BatchDisabled", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"status":403,"message":"Batch requests are not allowed.","data":{}}`))
+		}))
+		defer server.Close()
+
+		_, err := NewClient(server.URL).Batch().Delete("tasks", "t1").Send(ctx)
+		assert.ErrorIs(t, err, ErrForbidden)
+		var batchErr *BatchError
+		assert.False(t, errors.As(err, &batchErr))
+	})
+
+	t.Run("EmptyBatchSendsNothing", func(t *testing.T) {
+		results, err := NewClient("http://unreachable.invalid").Batch().Send(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
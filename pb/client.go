@@ -2,11 +2,13 @@ package pb
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -17,7 +19,7 @@ type Client struct {
 	AuthRecord *User
 }
 
-func (c *Client) newRequest(method, path string, body any) (*http.Request, error) {
+func (c *Client) newRequest(ctx context.Context, method, path string, body any) (*http.Request, error) {
 	var bodyReader io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -27,7 +29,7 @@ func (c *Client) newRequest(method, path string, body any) (*http.Request, error
 		bodyReader = bytes.NewBuffer(jsonBody)
 	}
 
-	req, err := http.NewRequest(method, c.BaseURL+path, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -43,10 +45,27 @@ func (c *Client) newRequest(method, path string, body any) (*http.Request, error
 	return req, nil
 }
 
-type User struct {
-	ID    string `json:"id"`
-	Email string `json:"email"`
-	Name  string `json:"name"`
+// do sends req and decodes a successful JSON response into out (skipped when out is nil).
+// Non-2xx responses are reported as "failed to <action>" errors wrapping an *APIError.
+func (c *Client) do(req *http.Request, action string, out any) error {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to %s: %w", action, newAPIError(resp))
+	}
+
+	if out == nil {
+		return nil
+	}
+	// Empty bodies (e.g. 204 No Content) leave out untouched
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
 }
 
 type authResponse struct {
@@ -54,25 +73,64 @@ type authResponse struct {
 	Record User   `json:"record"`
 }
 
-type Post struct {
-	ID      string `json:"id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
-	Author  string `json:"author"`
-	Public  bool   `json:"public"`
-	Created string `json:"created"`
-	Updated string `json:"updated"`
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*clientConfig)
+
+type clientConfig struct {
+	timeout     time.Duration
+	transport   http.RoundTripper
+	retry       RetryPolicy
+	breaker     BreakerConfig
+	middlewares []Middleware
+}
+
+// WithTimeout bounds each call, retries included. The default is 5 seconds.
+// A call that runs out of time fails with ErrUnavailable and counts against
+// the circuit breaker.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(cfg *clientConfig) { cfg.timeout = timeout }
+}
+
+// WithTransport sets the transport requests are finally sent through.
+// The default is http.DefaultTransport.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(cfg *clientConfig) { cfg.transport = transport }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(cfg *clientConfig) { cfg.retry = policy }
 }
 
-type listPostsResponse struct {
-	Items []Post `json:"items"`
+// WithCircuitBreaker replaces DefaultBreakerConfig. A zero Threshold disables the breaker.
+func WithCircuitBreaker(config BreakerConfig) ClientOption {
+	return func(cfg *clientConfig) { cfg.breaker = config }
 }
 
-func NewClient(url string) *Client {
+// NewClient returns a client for the PocketBase instance at url. Idempotent
+// requests are retried with jittered backoff, and a circuit breaker fails fast
+// with ErrUnavailable while PocketBase is down.
+func NewClient(url string, opts ...ClientOption) *Client {
+	cfg := clientConfig{
+		timeout:   5 * time.Second,
+		transport: http.DefaultTransport,
+		retry:     DefaultRetryPolicy,
+		breaker:   DefaultBreakerConfig,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	resilient := &resilientTransport{next: cfg.transport, retry: cfg.retry, timeout: cfg.timeout}
+	if cfg.breaker.Threshold > 0 {
+		resilient.breaker = newBreaker(cfg.breaker)
+	}
+
 	return &Client{
 		BaseURL: url,
 		HTTPClient: &http.Client{
-			Timeout: 5 * time.Second,
+			// The timeout is enforced by the resilient transport
+			Transport: chain(resilient, cfg.middlewares),
 		},
 	}
 }
@@ -108,6 +166,28 @@ func (c *Client) GetCurrentUserName() string {
 	return "User"
 }
 
+// GetCurrentUserAvatarURL returns the URL of the user's avatar file, or "" when none is set.
+func (c *Client) GetCurrentUserAvatarURL() string {
+	if c.AuthRecord == nil || c.AuthRecord.Avatar == "" {
+		return ""
+	}
+	collection := c.AuthRecord.CollectionID
+	if collection == "" {
+		collection = "users"
+	}
+	return c.FileURL(collection, c.AuthRecord.ID, c.AuthRecord.Avatar)
+}
+
+// FileURL builds the public URL of a file stored on a record.
+func (c *Client) FileURL(collection, recordID, filename string) string {
+	return c.BaseURL + "/api/files/" + url.PathEscape(collection) + "/" + url.PathEscape(recordID) + "/" + url.PathEscape(filename)
+}
+
+// IsVerified reports whether the current user has confirmed their email address.
+func (c *Client) IsVerified() bool {
+	return c.AuthRecord != nil && c.AuthRecord.Verified
+}
+
 func (c *Client) GetUserID() string {
 	if c.AuthRecord != nil {
 		return c.AuthRecord.ID
@@ -118,12 +198,18 @@ func (c *Client) GetUserID() string {
 // AuthWithPassword authenticates and returns (token, *User, error).
 // The token should be stored in a cookie by the caller.
 func (c *Client) AuthWithPassword(email, password string) (string, *User, error) {
+	return c.AuthWithPasswordContext(context.Background(), email, password)
+}
+
+// AuthWithPasswordContext is like AuthWithPassword but aborts the request
+// when ctx is cancelled or its deadline expires.
+func (c *Client) AuthWithPasswordContext(ctx context.Context, email, password string) (string, *User, error) {
 	body := map[string]any{
 		"identity": email,
 		"password": password,
 	}
 
-	req, err := c.newRequest("POST", "/api/collections/users/auth-with-password", body)
+	req, err := c.newRequest(ctx, "POST", "/api/collections/users/auth-with-password", body)
 	if err != nil {
 		return "", nil, err
 	}
@@ -135,7 +221,7 @@ func (c *Client) AuthWithPassword(email, password string) (string, *User, error)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", nil, errors.New("authentication failed")
+		return "", nil, newAuthError(resp)
 	}
 
 	var authResp authResponse
@@ -146,151 +232,168 @@ func (c *Client) AuthWithPassword(email, password string) (string, *User, error)
 	return authResp.Token, &authResp.Record, nil
 }
 
-func (c *Client) Logout() {
-	c.AuthToken = ""
-	c.AuthRecord = nil
-}
-
-func (c *Client) ListPosts() ([]Post, error) {
-	req, err := c.newRequest("GET", "/api/collections/posts/records", nil)
-	if err != nil {
-		return nil, err
+// AuthRefresh exchanges the client's still-valid token for a fresh one and
+// returns (token, *User, error). Dead tokens fail with ErrUnauthorized.
+func (c *Client) AuthRefresh(ctx context.Context) (string, *User, error) {
+	if c.AuthToken == "" {
+		return "", nil, ErrUnauthorized
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	req, err := c.newRequest(ctx, "POST", "/api/collections/users/auth-refresh", nil)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch posts: %d", resp.StatusCode)
+	var authResp authResponse
+	if err := c.do(req, "refresh auth token", &authResp); err != nil {
+		return "", nil, err
 	}
-
-	var listResp listPostsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if authResp.Token == "" {
+		return "", nil, errors.New("auth refresh returned no token")
 	}
 
-	return listResp.Items, nil
+	return authResp.Token, &authResp.Record, nil
 }
 
-func (c *Client) GetPost(id string) (*Post, error) {
-	req, err := c.newRequest("GET", "/api/collections/posts/records/"+id, nil)
-	if err != nil {
-		return nil, err
-	}
+// RequestPasswordReset asks PocketBase to email a password reset link.
+// PocketBase answers the same whether or not an account uses the address.
+func (c *Client) RequestPasswordReset(ctx context.Context, email string) error {
+	body := map[string]any{"email": email}
 
-	resp, err := c.HTTPClient.Do(req)
+	req, err := c.newRequest(ctx, http.MethodPost, "/api/collections/users/request-password-reset", body)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer resp.Body.Close()
+	return c.do(req, "request password reset", nil)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch post: %d", resp.StatusCode)
+// ConfirmPasswordReset sets a new password using the token from the reset email.
+func (c *Client) ConfirmPasswordReset(ctx context.Context, token, password, passwordConfirm string) error {
+	body := map[string]any{
+		"token":           token,
+		"password":        password,
+		"passwordConfirm": passwordConfirm,
 	}
 
-	var post Post
-	if err := json.NewDecoder(resp.Body).Decode(&post); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	req, err := c.newRequest(ctx, http.MethodPost, "/api/collections/users/confirm-password-reset", body)
+	if err != nil {
+		return err
 	}
-
-	return &post, nil
+	return c.do(req, "confirm password reset", nil)
 }
 
-func (c *Client) CreatePost(title, content string, isPublic bool) error {
-	if c.AuthToken == "" {
-		return errors.New("unauthorized")
-	}
-
-	body := map[string]interface{}{
-		"title":   title,
-		"content": content,
-		"author":  c.GetUserID(),
-		"public":  isPublic,
-	}
+// RequestVerification asks PocketBase to email an account verification link.
+// Like RequestPasswordReset, it does not reveal whether the address is registered.
+func (c *Client) RequestVerification(ctx context.Context, email string) error {
+	body := map[string]any{"email": email}
 
-	req, err := c.newRequest("POST", "/api/collections/posts/records", body)
+	req, err := c.newRequest(ctx, http.MethodPost, "/api/collections/users/request-verification", body)
 	if err != nil {
 		return err
 	}
+	return c.do(req, "request verification", nil)
+}
 
-	resp, err := c.HTTPClient.Do(req)
+// ConfirmVerification marks the account behind the emailed token as verified.
+func (c *Client) ConfirmVerification(ctx context.Context, token string) error {
+	body := map[string]any{"token": token}
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/api/collections/users/confirm-verification", body)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
+	return c.do(req, "confirm verification", nil)
+}
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to create post: %s", string(respBody))
-	}
+func (c *Client) Logout() {
+	c.AuthToken = ""
+	c.AuthRecord = nil
+}
 
-	return nil
+// Users returns a typed client for the users auth collection.
+func (c *Client) Users() *Collection[User] {
+	return NewCollection[User](c, UserCollection)
 }
 
-func (c *Client) DeletePost(id string) error {
+// Posts returns a typed client for the posts collection.
+func (c *Client) Posts() *Collection[Post] {
+	return NewCollection[Post](c, PostCollection)
+}
+
+func (c *Client) ListPosts() ([]Post, error) {
+	return c.ListPostsContext(context.Background())
+}
+
+// ListPostsContext is like ListPosts but honours ctx cancellation.
+// It follows pagination and returns every visible post.
+func (c *Client) ListPostsContext(ctx context.Context) ([]Post, error) {
+	return c.Posts().FullList(ctx, ListOptions{})
+}
+
+func (c *Client) GetPost(id string) (*Post, error) {
+	return c.GetPostContext(context.Background(), id)
+}
+
+// GetPostContext is like GetPost but honours ctx cancellation.
+func (c *Client) GetPostContext(ctx context.Context, id string) (*Post, error) {
+	return c.Posts().Get(ctx, id)
+}
+
+func (c *Client) CreatePost(title, content string, isPublic bool) error {
+	return c.CreatePostContext(context.Background(), title, content, isPublic)
+}
+
+// CreatePostContext is like CreatePost but honours ctx cancellation.
+func (c *Client) CreatePostContext(ctx context.Context, title, content string, isPublic bool) error {
 	if c.AuthToken == "" {
-		return errors.New("unauthorized")
+		return ErrUnauthorized
 	}
 
-	req, err := c.newRequest("DELETE", "/api/collections/posts/records/"+id, nil)
-	if err != nil {
-		return err
+	body := map[string]interface{}{
+		"title":   title,
+		"content": content,
+		"author":  c.GetUserID(),
+		"public":  isPublic,
 	}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
+	_, err := c.Posts().Create(ctx, body)
+	return err
+}
 
-	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to delete post: %d", resp.StatusCode)
-	}
-	return nil
+func (c *Client) DeletePost(id string) error {
+	return c.DeletePostContext(context.Background(), id)
 }
 
-func (c *Client) UpdatePost(id string, data map[string]any) error {
+// DeletePostContext is like DeletePost but honours ctx cancellation.
+func (c *Client) DeletePostContext(ctx context.Context, id string) error {
 	if c.AuthToken == "" {
-		return errors.New("unauthorized")
+		return ErrUnauthorized
 	}
 
-	req, err := c.newRequest("PATCH", "/api/collections/posts/records/"+id, data)
-	if err != nil {
-		return err
-	}
+	return c.Posts().Delete(ctx, id)
+}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
+func (c *Client) UpdatePost(id string, data map[string]any) error {
+	return c.UpdatePostContext(context.Background(), id, data)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to update post: %d", resp.StatusCode)
+// UpdatePostContext is like UpdatePost but honours ctx cancellation.
+func (c *Client) UpdatePostContext(ctx context.Context, id string, data map[string]any) error {
+	if c.AuthToken == "" {
+		return ErrUnauthorized
 	}
-	return nil
+
+	_, err := c.Posts().Update(ctx, id, data)
+	return err
 }
 
 // CreateRecord generic helper for registration
 func (c *Client) CreateRecord(collection string, data map[string]any) error {
-	req, err := c.newRequest("POST", "/api/collections/"+collection+"/records", data)
-	if err != nil {
-		return err
-	}
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to create record: %s", string(respBody))
-	}
+	return c.CreateRecordContext(context.Background(), collection, data)
+}
 
-	return nil
+// CreateRecordContext is like CreateRecord but honours ctx cancellation.
+func (c *Client) CreateRecordContext(ctx context.Context, collection string, data map[string]any) error {
+	_, err := NewCollection[map[string]any](c, collection).Create(ctx, data)
+	return err
 }
@@ -1,10 +1,12 @@
 package pb
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -46,7 +48,7 @@ func TestListPosts(t *testing.T) {
 		assert.Equal(t, "/api/collections/posts/records", r.URL.Path)
 		assert.Equal(t, "GET", r.Method)
 
-		resp := listPostsResponse{
+		resp := ListResult[Post]{
 			Items: []Post{
 				{ID: "1", Title: "Post 1"},
 				{ID: "2", Title: "Post 2"},
@@ -85,3 +87,71 @@ func TestCreatePost(t *testing.T) {
 
 	assert.NoError(t, err)
 }
+
+func TestCancelledContextAbortsRequest(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(server.URL).WithToken("test-token")
+
+	t.Run("AlreadyCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := client.ListPostsContext(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("CancelledInFlight", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := client.GetPostContext(ctx, "p1")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("DeadlineExceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := client.UpdatePostContext(ctx, "p1", map[string]any{"title": "Updated"})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestPasswordReset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/api/collections/users/request-password-reset":
+			assert.Equal(t, "test@example.com", body["email"])
+		case "/api/collections/users/confirm-password-reset":
+			assert.Equal(t, "reset-token", body["token"])
+			assert.Equal(t, "new-password", body["password"])
+			assert.Equal(t, "new-password", body["passwordConfirm"])
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	assert.NoError(t, client.RequestPasswordReset(ctx, "test@example.com"))
+	assert.NoError(t, client.ConfirmPasswordReset(ctx, "reset-token", "new-password", "new-password"))
+}
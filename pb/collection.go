@@ -0,0 +1,149 @@
+package pb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// ErrNotFound is returned when a lookup matches no record.
+var ErrNotFound = errors.New("record not found")
+
+// Collection is a typed client for the records of a single PocketBase collection.
+// T is the record type responses are decoded into.
+type Collection[T any] struct {
+	client *Client
+	name   string
+}
+
+// NewCollection returns a typed client for the named collection.
+// Requests are sent with the given client's token.
+func NewCollection[T any](client *Client, name string) *Collection[T] {
+	return &Collection[T]{client: client, name: name}
+}
+
+// Name returns the collection name.
+func (c *Collection[T]) Name() string {
+	return c.name
+}
+
+func (c *Collection[T]) recordsPath() string {
+	return "/api/collections/" + url.PathEscape(c.name) + "/records"
+}
+
+func (c *Collection[T]) recordPath(id string) string {
+	return c.recordsPath() + "/" + url.PathEscape(id)
+}
+
+// List returns one page of records matching opts together with the pagination metadata.
+func (c *Collection[T]) List(ctx context.Context, opts ListOptions) (*ListResult[T], error) {
+	path := c.recordsPath()
+	if query := opts.query(); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	req, err := c.client.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ListResult[T]
+	if err := c.client.do(req, "list "+c.name+" records", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// FullList walks every page matching opts and returns all records.
+// opts.Page is ignored; opts.PerPage sets the batch size (defaults to 500).
+// Paging follows the reported totalPages, so a server that caps perPage
+// below the requested size still returns every record.
+func (c *Collection[T]) FullList(ctx context.Context, opts ListOptions) ([]T, error) {
+	if opts.PerPage <= 0 {
+		opts.PerPage = 500
+	}
+	opts.SkipTotal = false
+
+	var items []T
+	for page := 1; ; page++ {
+		opts.Page = page
+		result, err := c.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, result.Items...)
+		if page >= result.TotalPages || len(result.Items) == 0 {
+			return items, nil
+		}
+	}
+}
+
+// First returns the first record matching the PocketBase filter expression,
+// or ErrNotFound when nothing matches.
+func (c *Collection[T]) First(ctx context.Context, filter string) (*T, error) {
+	result, err := c.List(ctx, ListOptions{
+		Page:      1,
+		PerPage:   1,
+		Filter:    filter,
+		SkipTotal: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Items) == 0 {
+		return nil, ErrNotFound
+	}
+	return &result.Items[0], nil
+}
+
+// Get returns the record with the given id.
+func (c *Collection[T]) Get(ctx context.Context, id string) (*T, error) {
+	req, err := c.client.newRequest(ctx, http.MethodGet, c.recordPath(id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var record T
+	if err := c.client.do(req, "fetch "+c.name+" record", &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Create inserts a record built from data (a struct or map) and returns the stored record.
+func (c *Collection[T]) Create(ctx context.Context, data any) (*T, error) {
+	req, err := c.client.newRequest(ctx, http.MethodPost, c.recordsPath(), data)
+	if err != nil {
+		return nil, err
+	}
+
+	var record T
+	if err := c.client.do(req, "create "+c.name+" record", &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Update patches the record with the given id and returns the stored record.
+func (c *Collection[T]) Update(ctx context.Context, id string, data any) (*T, error) {
+	req, err := c.client.newRequest(ctx, http.MethodPatch, c.recordPath(id), data)
+	if err != nil {
+		return nil, err
+	}
+
+	var record T
+	if err := c.client.do(req, "update "+c.name+" record", &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Delete removes the record with the given id.
+func (c *Collection[T]) Delete(ctx context.Context, id string) error {
+	req, err := c.client.newRequest(ctx, http.MethodDelete, c.recordPath(id), nil)
+	if err != nil {
+		return err
+	}
+	return c.client.do(req, "delete "+c.name+" record", nil)
+}
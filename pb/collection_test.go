@@ -0,0 +1,238 @@
+package pb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testTask struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Done bool   `json:"done"`
+}
+
+func TestCollection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("List", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/collections/tasks/records", r.URL.Path)
+			assert.Equal(t, "GET", r.Method)
+			json.NewEncoder(w).Encode(map[string]any{
+				"items": []map[string]any{
+					{"id": "t1", "name": "Calibrate", "done": true},
+					{"id": "t2", "name": "Refuel"},
+				},
+			})
+		}))
+		defer server.Close()
+
+		tasks := NewCollection[testTask](NewClient(server.URL), "tasks")
+		result, err := tasks.List(ctx, ListOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []testTask{{ID: "t1", Name: "Calibrate", Done: true}, {ID: "t2", Name: "Refuel"}}, result.Items)
+	})
+
+	t.Run("ListWithOptions", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			assert.Equal(t, "2", query.Get("page"))
+			assert.Equal(t, "10", query.Get("perPage"))
+			assert.Equal(t, "done = false", query.Get("filter"))
+			assert.Equal(t, "-created", query.Get("sort"))
+			assert.Equal(t, "owner", query.Get("expand"))
+			assert.Equal(t, "id,name", query.Get("fields"))
+			assert.False(t, query.Has("skipTotal"))
+
+			json.NewEncoder(w).Encode(map[string]any{
+				"page":       2,
+				"perPage":    10,
+				"totalItems": 25,
+				"totalPages": 3,
+				"items":      []map[string]any{{"id": "t11", "name": "Refuel"}},
+			})
+		}))
+		defer server.Close()
+
+		tasks := NewCollection[testTask](NewClient(server.URL), "tasks")
+		result, err := tasks.List(ctx, ListOptions{
+			Page:    2,
+			PerPage: 10,
+			Filter:  "done = false",
+			Sort:    "-created",
+			Expand:  "owner",
+			Fields:  "id,name",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Page)
+		assert.Equal(t, 10, result.PerPage)
+		assert.Equal(t, 25, result.TotalItems)
+		assert.Equal(t, 3, result.TotalPages)
+		assert.True(t, result.HasMore())
+		assert.Len(t, result.Items, 1)
+	})
+
+	t.Run("FullList", func(t *testing.T) {
+		var pages []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			pages = append(pages, query.Get("page"))
+			assert.Equal(t, "2", query.Get("perPage"))
+			assert.False(t, query.Has("skipTotal"), "paging needs totalPages")
+
+			items := []map[string]any{{"id": "a"}, {"id": "b"}}
+			if query.Get("page") == "2" {
+				items = items[:1]
+			}
+			json.NewEncoder(w).Encode(map[string]any{"totalItems": 3, "totalPages": 2, "items": items})
+		}))
+		defer server.Close()
+
+		tasks := NewCollection[testTask](NewClient(server.URL), "tasks")
+		items, err := tasks.FullList(ctx, ListOptions{PerPage: 2})
+
+		assert.NoError(t, err)
+		assert.Len(t, items, 3)
+		assert.Equal(t, []string{"1", "2"}, pages)
+	})
+
+	t.Run("FullListCappedPerPage", func(t *testing.T) {
+		// The server ignores perPage=500 and pages by 2, as with a lowered PocketBase limit
+		all := []map[string]any{{"id": "a"}, {"id": "b"}, {"id": "c"}, {"id": "d"}, {"id": "e"}}
+		var pages []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page := r.URL.Query().Get("page")
+			pages = append(pages, page)
+			start := map[string]int{"1": 0, "2": 2, "3": 4}[page]
+			json.NewEncoder(w).Encode(map[string]any{
+				"perPage":    2,
+				"totalItems": len(all),
+				"totalPages": 3,
+				"items":      all[start:min(start+2, len(all))],
+			})
+		}))
+		defer server.Close()
+
+		tasks := NewCollection[testTask](NewClient(server.URL), "tasks")
+		items, err := tasks.FullList(ctx, ListOptions{})
+
+		assert.NoError(t, err)
+		assert.Len(t, items, 5, "a short first page is not the last one")
+		assert.Equal(t, []string{"1", "2", "3"}, pages)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/collections/tasks/records/t1", r.URL.Path)
+			assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+			json.NewEncoder(w).Encode(map[string]any{"id": "t1", "name": "Calibrate"})
+		}))
+		defer server.Close()
+
+		tasks := NewCollection[testTask](NewClient(server.URL).WithToken("test-token"), "tasks")
+		task, err := tasks.Get(ctx, "t1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Calibrate", task.Name)
+	})
+
+	t.Run("GetError", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		tasks := NewCollection[testTask](NewClient(server.URL), "tasks")
+		task, err := tasks.Get(ctx, "missing")
+
+		assert.Error(t, err)
+		assert.Nil(t, task)
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/collections/tasks/records", r.URL.Path)
+			assert.Equal(t, "POST", r.Method)
+
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, "Refuel", body["name"])
+
+			body["id"] = "t3"
+			json.NewEncoder(w).Encode(body)
+		}))
+		defer server.Close()
+
+		tasks := NewCollection[testTask](NewClient(server.URL), "tasks")
+		task, err := tasks.Create(ctx, testTask{Name: "Refuel"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "t3", task.ID)
+		assert.Equal(t, "Refuel", task.Name)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/collections/tasks/records/t1", r.URL.Path)
+			assert.Equal(t, "PATCH", r.Method)
+			json.NewEncoder(w).Encode(map[string]any{"id": "t1", "name": "Calibrate", "done": true})
+		}))
+		defer server.Close()
+
+		tasks := NewCollection[testTask](NewClient(server.URL), "tasks")
+		task, err := tasks.Update(ctx, "t1", map[string]any{"done": true})
+
+		assert.NoError(t, err)
+		assert.True(t, task.Done)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/collections/tasks/records/t1", r.URL.Path)
+			assert.Equal(t, "DELETE", r.Method)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		tasks := NewCollection[testTask](NewClient(server.URL), "tasks")
+		assert.NoError(t, tasks.Delete(ctx, "t1"))
+	})
+
+	t.Run("First", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/collections/tasks/records", r.URL.Path)
+			assert.Equal(t, "done = true", r.URL.Query().Get("filter"))
+			assert.Equal(t, "1", r.URL.Query().Get("perPage"))
+			json.NewEncoder(w).Encode(map[string]any{
+				"items": []map[string]any{{"id": "t1", "name": "Calibrate", "done": true}},
+			})
+		}))
+		defer server.Close()
+
+		tasks := NewCollection[testTask](NewClient(server.URL), "tasks")
+		task, err := tasks.First(ctx, "done = true")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "t1", task.ID)
+	})
+
+	t.Run("FirstNotFound", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{}})
+		}))
+		defer server.Close()
+
+		tasks := NewCollection[testTask](NewClient(server.URL), "tasks")
+		task, err := tasks.First(ctx, "done = true")
+
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Nil(t, task)
+	})
+}
@@ -0,0 +1,107 @@
+package pb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Sentinel errors matched by APIError via errors.Is.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+)
+
+// FieldError is PocketBase's validation failure for a single field.
+type FieldError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// APIError is a non-2xx response from PocketBase, decoded from its
+// {"status", "message", "data"} error envelope.
+type APIError struct {
+	Status  int                   `json:"status"`
+	Message string                `json:"message"`
+	Data    map[string]FieldError `json:"data"`
+}
+
+func (e *APIError) Error() string {
+	if len(e.Data) == 0 {
+		return fmt.Sprintf("pocketbase: %d %s", e.Status, e.Message)
+	}
+
+	fields := make([]string, 0, len(e.Data))
+	for name, fe := range e.Data {
+		fields = append(fields, name+": "+fe.Message)
+	}
+	sort.Strings(fields)
+	return fmt.Sprintf("pocketbase: %d %s (%s)", e.Status, e.Message, strings.Join(fields, "; "))
+}
+
+// Is lets errors.Is match an APIError against the status sentinels
+// (ErrBadRequest, ErrUnauthorized, ErrForbidden, ErrNotFound, ErrUnavailable).
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.Status == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.Status == http.StatusForbidden
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrUnavailable:
+		return isUnavailableStatus(e.Status)
+	}
+	return false
+}
+
+// FieldErrors returns the validation message for each invalid field.
+func (e *APIError) FieldErrors() map[string]string {
+	if len(e.Data) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]string, len(e.Data))
+	for name, fe := range e.Data {
+		fields[name] = fe.Message
+	}
+	return fields
+}
+
+// FieldErrors extracts per-field validation messages from err.
+// It returns nil when err does not wrap an APIError with field details.
+func FieldErrors(err error) map[string]string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.FieldErrors()
+	}
+	return nil
+}
+
+// newAPIError decodes the error envelope of resp, falling back to the raw
+// body (or the status text) when PocketBase did not send JSON.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{Status: resp.StatusCode}
+
+	body, _ := io.ReadAll(resp.Body)
+	_ = json.Unmarshal(body, apiErr)
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	// The envelope status wins unless it was missing
+	if apiErr.Status == 0 {
+		apiErr.Status = resp.StatusCode
+	}
+
+	return apiErr
+}
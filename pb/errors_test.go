@@ -0,0 +1,95 @@
+package pb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("DecodesValidationEnvelope", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{
+				"status": 400,
+				"message": "Failed to create record.",
+				"data": {
+					"email": {"code": "validation_not_unique", "message": "Value must be unique."},
+					"password": {"code": "validation_length_out_of_range", "message": "The length must be between 8 and 72."}
+				}
+			}`))
+		}))
+		defer server.Close()
+
+		err := NewClient(server.URL).CreateRecord("users", map[string]any{"email": "taken@example.com"})
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, 400, apiErr.Status)
+		assert.Equal(t, "Failed to create record.", apiErr.Message)
+		assert.Equal(t, "validation_not_unique", apiErr.Data["email"].Code)
+		assert.ErrorIs(t, err, ErrBadRequest)
+		assert.NotErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, map[string]string{
+			"email":    "Value must be unique.",
+			"password": "The length must be between 8 and 72.",
+		}, FieldErrors(err))
+		assert.Contains(t, err.Error(), "email: Value must be unique.; password:")
+	})
+
+	t.Run("MapsStatusSentinels", func(t *testing.T) {
+		cases := map[int]error{
+			http.StatusUnauthorized:       ErrUnauthorized,
+			http.StatusForbidden:          ErrForbidden,
+			http.StatusNotFound:           ErrNotFound,
+			http.StatusServiceUnavailable: ErrUnavailable,
+		}
+		for status, sentinel := range cases {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+				fmt.Fprintf(w, `{"status": %d, "message": "nope", "data": {}}`, status)
+			}))
+
+			_, err := NewClient(server.URL).Posts().Get(ctx, "p1")
+			assert.ErrorIs(t, err, sentinel, "status %d", status)
+			assert.Nil(t, FieldErrors(err))
+			server.Close()
+		}
+	})
+
+	t.Run("FallsBackToRawBody", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("upstream unavailable"))
+		}))
+		defer server.Close()
+
+		_, err := NewClient(server.URL).Posts().List(ctx, ListOptions{})
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusBadGateway, apiErr.Status)
+		assert.Equal(t, "upstream unavailable", apiErr.Message)
+	})
+
+	t.Run("AuthWithPasswordFailure", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status": 400, "message": "Failed to authenticate.", "data": {}}`))
+		}))
+		defer server.Close()
+
+		_, _, err := NewClient(server.URL).AuthWithPassword("test@example.com", "wrong")
+
+		assert.ErrorIs(t, err, ErrBadRequest)
+		assert.Contains(t, err.Error(), "Failed to authenticate.")
+	})
+}
@@ -0,0 +1,143 @@
+package pb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Params are the values substituted into a Filter expression.
+type Params map[string]any
+
+// Filter builds a filter expression from expr by replacing each {:name}
+// placeholder with the literal form of params[name], like the JS SDK's
+// pb.filter. Strings are quoted, so user input can be passed safely:
+//
+//	pb.Filter("title ~ {:q} && created > {:since}", pb.Params{"q": q, "since": t})
+func Filter(expr string, params Params) string {
+	// Longest names first so {:ab} is not clobbered by {:a}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	pairs := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		pairs = append(pairs, "{:"+name+"}", literal(params[name]))
+	}
+	return strings.NewReplacer(pairs...).Replace(expr)
+}
+
+// Quote returns s as a single-quoted filter string literal. PocketBase only
+// knows the \' escape and ends a string at the first quote not preceded by a
+// backslash, so a quote in s cannot close the literal early. Trailing
+// backslashes would escape the closing quote and are dropped.
+func Quote(s string) string {
+	s = strings.TrimRight(s, `\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+// literal formats a Go value as a filter literal.
+func literal(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return Quote(v)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	case time.Time:
+		return Quote(v.UTC().Format(DateLayout))
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Quote(fmt.Sprint(value))
+	}
+	return Quote(string(data))
+}
+
+// DateLayout is the format of PocketBase date and autodate values.
+const DateLayout = "2006-01-02 15:04:05.000Z"
+
+// And joins the non-empty filters with &&, grouping each in parentheses.
+func And(filters ...string) string {
+	return join(" && ", filters)
+}
+
+// Or joins the non-empty filters with ||, grouping each in parentheses.
+func Or(filters ...string) string {
+	return join(" || ", filters)
+}
+
+func join(op string, filters []string) string {
+	var parts []string
+	for _, filter := range filters {
+		if filter != "" {
+			parts = append(parts, filter)
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	for i, part := range parts {
+		parts[i] = "(" + part + ")"
+	}
+	return strings.Join(parts, op)
+}
+
+// The field types below are used by the generated <Record>Filter helpers to
+// build type-checked conditions, e.g. pb.PostFilter.Public.Is(true).
+
+// Field is a field of any type; values are formatted like Filter params.
+type Field string
+
+func (f Field) Eq(value any) string    { return compare(string(f), "=", value) }
+func (f Field) NotEq(value any) string { return compare(string(f), "!=", value) }
+
+// TextField is a text, email, url, editor or single select field.
+type TextField string
+
+func (f TextField) Eq(value string) string    { return compare(string(f), "=", value) }
+func (f TextField) NotEq(value string) string { return compare(string(f), "!=", value) }
+
+// Like matches values containing value, case-insensitively.
+func (f TextField) Like(value string) string { return compare(string(f), "~", value) }
+
+// BoolField is a bool field.
+type BoolField string
+
+func (f BoolField) Is(value bool) string { return compare(string(f), "=", value) }
+
+// NumberField is a number field.
+type NumberField string
+
+func (f NumberField) Eq(value float64) string  { return compare(string(f), "=", value) }
+func (f NumberField) Gt(value float64) string  { return compare(string(f), ">", value) }
+func (f NumberField) Gte(value float64) string { return compare(string(f), ">=", value) }
+func (f NumberField) Lt(value float64) string  { return compare(string(f), "<", value) }
+func (f NumberField) Lte(value float64) string { return compare(string(f), "<=", value) }
+
+// DateField is a date or autodate field.
+type DateField string
+
+// Before matches dates strictly before t.
+func (f DateField) Before(t time.Time) string { return compare(string(f), "<", t) }
+
+// After matches dates at or after t.
+func (f DateField) After(t time.Time) string { return compare(string(f), ">=", t) }
+
+// RelationField is a relation field.
+type RelationField string
+
+// Is matches records related to id, for single relations.
+func (f RelationField) Is(id string) string { return compare(string(f), "=", id) }
+
+// Has matches records whose relation list contains id.
+func (f RelationField) Has(id string) string { return compare(string(f), "?=", id) }
+
+func compare(field, op string, value any) string {
+	return field + " " + op + " " + literal(value)
+}
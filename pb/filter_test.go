@@ -0,0 +1,63 @@
+package pb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	since := time.Date(2026, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name   string
+		expr   string
+		params Params
+		want   string
+	}{
+		{"String", "title ~ {:q}", Params{"q": "warp"}, "title ~ 'warp'"},
+		{"QuoteEscaped", "title = {:q}", Params{"q": "it's"}, `title = 'it\'s'`},
+		{"Injection", "author = {:id}", Params{"id": "x' || public = false || '"}, `author = 'x\' || public = false || \''`},
+		{"Bool", "public = {:p}", Params{"p": true}, "public = true"},
+		{"Number", "version > {:v}", Params{"v": 2}, "version > 2"},
+		{"Nil", "avatar = {:a}", Params{"a": nil}, "avatar = null"},
+		{"Time", "created >= {:since}", Params{"since": since}, "created >= '2026-03-01 08:30:00.000Z'"},
+		{"JSON", "tags = {:t}", Params{"t": []string{"a"}}, `tags = '["a"]'`},
+		{"SimilarNames", "{:a} {:ab}", Params{"a": "1", "ab": "2"}, "'1' '2'"},
+		{"Unknown", "title = {:missing}", Params{}, "title = {:missing}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Filter(tt.expr, tt.params))
+		})
+	}
+}
+
+func TestAndOr(t *testing.T) {
+	assert.Equal(t, "", And())
+	assert.Equal(t, "public = true", And("", "public = true"))
+	assert.Equal(t, "(a = 1) && ((b = 2) || (c = 3))", And("a = 1", Or("b = 2", "c = 3")))
+	assert.Equal(t, "((a = 1) && (b = 2)) || (c = 3)", Or(And("a = 1", "b = 2"), "c = 3"))
+}
+
+func TestFilterFields(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.Equal(t, "title ~ 'log'", PostFilter.Title.Like("log"))
+	assert.Equal(t, "title != 'x'", PostFilter.Title.NotEq("x"))
+	assert.Equal(t, "public = false", PostFilter.Public.Is(false))
+	assert.Equal(t, "author = 'abc'", PostFilter.Author.Is("abc"))
+	assert.Equal(t, "author ?= 'abc'", PostFilter.Author.Has("abc"))
+	assert.Equal(t, "created < '2026-01-02 03:04:05.000Z'", PostFilter.Created.Before(at))
+	assert.Equal(t, "created >= '2026-01-02 03:04:05.000Z'", PostFilter.Created.After(at))
+	assert.Equal(t, "version <= 3", NumberField("version").Lte(3))
+	assert.Equal(t, "avatar = null", Field("avatar").Eq(nil))
+}
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, `'plain'`, Quote("plain"))
+	assert.Equal(t, `'it\'s'`, Quote("it's"))
+	assert.Equal(t, `'a\\'b'`, Quote(`a\'b`), "an escaped quote stays inside the literal")
+	assert.Equal(t, `'dir'`, Quote(`dir\\`), "trailing backslashes would escape the closing quote")
+}
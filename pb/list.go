@@ -0,0 +1,62 @@
+package pb
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// ListOptions controls which records a list request returns.
+// Zero values are omitted so PocketBase applies its own defaults.
+type ListOptions struct {
+	Page      int    // 1-based page number
+	PerPage   int    // Records per page (PocketBase defaults to 30)
+	Filter    string // Filter expression, e.g. `public = true && author = "abc"`
+	Sort      string // Comma-separated fields, "-" prefix for DESC, e.g. "-created,title"
+	Expand    string // Relations to expand, e.g. "author"
+	Fields    string // Comma-separated fields to return
+	SkipTotal bool   // Skip the total count query (TotalItems/TotalPages will be -1)
+}
+
+func (o ListOptions) query() url.Values {
+	query := url.Values{}
+	if o.Page > 0 {
+		query.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(o.PerPage))
+	}
+	if o.Filter != "" {
+		query.Set("filter", o.Filter)
+	}
+	if o.Sort != "" {
+		query.Set("sort", o.Sort)
+	}
+	if o.Expand != "" {
+		query.Set("expand", o.Expand)
+	}
+	if o.Fields != "" {
+		query.Set("fields", o.Fields)
+	}
+	if o.SkipTotal {
+		query.Set("skipTotal", "true")
+	}
+	return query
+}
+
+// ListResult is one page of records plus PocketBase's pagination metadata.
+type ListResult[T any] struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"perPage"`
+	TotalItems int `json:"totalItems"`
+	TotalPages int `json:"totalPages"`
+	Items      []T `json:"items"`
+}
+
+// HasMore reports whether pages after this one exist.
+func (r *ListResult[T]) HasMore() bool {
+	if r.TotalPages < 0 {
+		// Totals were skipped; a full page suggests there may be more
+		return r.PerPage > 0 && len(r.Items) >= r.PerPage
+	}
+	return r.Page < r.TotalPages
+}
@@ -0,0 +1,88 @@
+package pb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrMFARequired is matched by errors.Is when the password was accepted but
+// PocketBase requires a second factor. Use errors.As with *MFAError to get the mfaId.
+var ErrMFARequired = errors.New("multi-factor authentication required")
+
+// MFAError is returned by AuthWithPassword when the first factor succeeded and
+// the sign-in must be completed with another method, e.g. AuthWithOTP.
+type MFAError struct {
+	MFAID string
+}
+
+func (e *MFAError) Error() string {
+	return "pocketbase: " + ErrMFARequired.Error()
+}
+
+func (e *MFAError) Is(target error) bool {
+	return target == ErrMFARequired
+}
+
+// newAuthError is newAPIError for auth endpoints, recognising PocketBase's
+// 401 response that carries an mfaId instead of a plain rejection.
+func newAuthError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+
+	var envelope struct {
+		Data struct {
+			MFAID string `json:"mfaId"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Data.MFAID != "" {
+		return &MFAError{MFAID: envelope.Data.MFAID}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return newAPIError(resp)
+}
+
+// RequestOTP emails a one-time code to the user and returns the otpId that
+// AuthWithOTP needs alongside the code.
+func (c *Client) RequestOTP(ctx context.Context, email string) (string, error) {
+	body := map[string]any{"email": email}
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/api/collections/users/request-otp", body)
+	if err != nil {
+		return "", err
+	}
+
+	var otp struct {
+		OTPID string `json:"otpId"`
+	}
+	if err := c.do(req, "request otp", &otp); err != nil {
+		return "", err
+	}
+	return otp.OTPID, nil
+}
+
+// AuthWithOTP signs in with an emailed one-time code. mfaID completes a
+// multi-factor sign-in started by AuthWithPassword; pass "" for OTP-only login.
+func (c *Client) AuthWithOTP(ctx context.Context, otpID, code, mfaID string) (string, *User, error) {
+	body := map[string]any{
+		"otpId":    otpID,
+		"password": code,
+	}
+	if mfaID != "" {
+		body["mfaId"] = mfaID
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/api/collections/users/auth-with-otp", body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var authResp authResponse
+	if err := c.do(req, "authenticate with otp", &authResp); err != nil {
+		return "", nil, err
+	}
+	return authResp.Token, &authResp.Record, nil
+}
@@ -0,0 +1,75 @@
+package pb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthWithPasswordMFA(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+
+		w.WriteHeader(http.StatusUnauthorized)
+		if body["password"] == "password123" {
+			w.Write([]byte(`{"status": 401, "message": "Please complete the MFA authentication.", "data": {"mfaId": "mfa-1"}}`))
+			return
+		}
+		w.Write([]byte(`{"status": 400, "message": "Failed to authenticate.", "data": {}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	_, _, err := client.AuthWithPassword("test@example.com", "password123")
+	assert.ErrorIs(t, err, ErrMFARequired)
+	var mfaErr *MFAError
+	require.ErrorAs(t, err, &mfaErr)
+	assert.Equal(t, "mfa-1", mfaErr.MFAID)
+
+	_, _, err = client.AuthWithPassword("test@example.com", "wrong")
+	assert.NotErrorIs(t, err, ErrMFARequired)
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestOTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/api/collections/users/request-otp":
+			assert.Equal(t, "test@example.com", body["email"])
+			w.Write([]byte(`{"otpId": "otp-1"}`))
+		case "/api/collections/users/auth-with-otp":
+			assert.Equal(t, "otp-1", body["otpId"])
+			assert.Equal(t, "123456", body["password"])
+			assert.Equal(t, "mfa-1", body["mfaId"])
+			json.NewEncoder(w).Encode(map[string]any{
+				"token":  "mfa-token",
+				"record": map[string]any{"id": "user-123"},
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	otpID, err := client.RequestOTP(ctx, "test@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "otp-1", otpID)
+
+	token, user, err := client.AuthWithOTP(ctx, otpID, "123456", "mfa-1")
+	require.NoError(t, err)
+	assert.Equal(t, "mfa-token", token)
+	assert.Equal(t, "user-123", user.ID)
+}
@@ -0,0 +1,87 @@
+package pb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAuthMethods(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/collections/users/auth-methods", r.URL.Path)
+		w.Write([]byte(`{
+			"password": {"enabled": true, "identityFields": ["email"]},
+			"oauth2": {"enabled": true, "providers": [{
+				"name": "github",
+				"displayName": "GitHub",
+				"state": "s1",
+				"codeVerifier": "v1",
+				"codeChallenge": "c1",
+				"codeChallengeMethod": "S256",
+				"authURL": "https://github.com/login/oauth/authorize?state=s1&redirect_uri="
+			}]}
+		}`))
+	}))
+	defer server.Close()
+
+	methods, err := NewClient(server.URL).ListAuthMethods(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, methods.Password.Enabled)
+	require.Len(t, methods.OAuth2.Providers, 1)
+	provider := methods.OAuth2.Providers[0]
+	assert.Equal(t, "GitHub", provider.DisplayName)
+	assert.Equal(t, "v1", provider.CodeVerifier)
+	assert.Equal(t,
+		"https://github.com/login/oauth/authorize?state=s1&redirect_uri=http%3A%2F%2Flocalhost%3A8080%2Fauth%2Foauth2%2Fcallback",
+		provider.AuthURLWithRedirect("http://localhost:8080/auth/oauth2/callback"))
+}
+
+func TestAuthWithOAuth2(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/collections/users/auth-with-oauth2", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["code"] == "mfa-code" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status": 401, "message": "Please complete the MFA flow.", "data": {"mfaId": "mfa-1"}}`))
+			return
+		}
+		if body["code"] != "good-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status": 400, "message": "Failed to authenticate.", "data": {}}`))
+			return
+		}
+		assert.Equal(t, "github", body["provider"])
+		assert.Equal(t, "v1", body["codeVerifier"])
+		assert.Equal(t, "http://app/callback", body["redirectURL"])
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"token":  "oauth2-token",
+			"record": map[string]any{"id": "u1", "email": "test@example.com"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	token, user, err := client.AuthWithOAuth2(context.Background(), "github", "good-code", "v1", "http://app/callback")
+	require.NoError(t, err)
+	assert.Equal(t, "oauth2-token", token)
+	assert.Equal(t, "u1", user.ID)
+
+	_, _, err = client.AuthWithOAuth2(context.Background(), "github", "bad-code", "v1", "http://app/callback")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	_, _, err = client.AuthWithOAuth2(context.Background(), "github", "mfa-code", "v1", "http://app/callback")
+	var mfaErr *MFAError
+	require.ErrorAs(t, err, &mfaErr, "a second factor is required")
+	assert.Equal(t, "mfa-1", mfaErr.MFAID)
+}
@@ -0,0 +1,6 @@
+package pb
+
+// WebAuthnCredentials returns a typed client for the webauthn_credentials collection.
+func (c *Client) WebAuthnCredentials() *Collection[WebAuthnCredential] {
+	return NewCollection[WebAuthnCredential](c, WebAuthnCredentialCollection)
+}
@@ -0,0 +1,264 @@
+package pb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Reconnect backoff bounds for dropped realtime connections.
+var (
+	realtimeMinBackoff = 250 * time.Millisecond
+	realtimeMaxBackoff = 30 * time.Second
+)
+
+// realtimeHandshakeTimeout bounds each connection attempt: opening the stream,
+// waiting for PB_CONNECT and setting the subscriptions. The stream itself has
+// no deadline once the handshake is done.
+var realtimeHandshakeTimeout = 10 * time.Second
+
+// RealtimeEvent is a record change pushed by PocketBase's realtime API.
+type RealtimeEvent[T any] struct {
+	Topic  string `json:"-"`      // Subscription the event was delivered for, e.g. "posts" or "posts/<id>"
+	Action string `json:"action"` // "create", "update" or "delete"
+	Record T      `json:"record"`
+}
+
+// Subscribe connects to /api/realtime, subscribes to topics and delivers every
+// record change decoded into T on the returned channel.
+//
+// The subscription is authorized with the client's token, so PocketBase only
+// sends records the user may view. Dropped connections are re-established with
+// backoff and the topics resubscribed under the new clientId. A handshake that
+// takes longer than realtimeHandshakeTimeout fails with ErrUnavailable, or is
+// retried with the same backoff when reconnecting. The channel is closed once
+// ctx is cancelled.
+func Subscribe[T any](ctx context.Context, c *Client, topics ...string) (<-chan RealtimeEvent[T], error) {
+	if len(topics) == 0 {
+		return nil, errors.New("at least one realtime topic is required")
+	}
+
+	rt := &realtime{
+		client: c,
+		topics: topics,
+		// Streams are long-lived, so skip HTTPClient's timeout but keep its transport
+		stream: &http.Client{Transport: c.HTTPClient.Transport},
+	}
+
+	body, events, err := rt.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan RealtimeEvent[T])
+	go runRealtime(ctx, rt, body, events, out)
+	return out, nil
+}
+
+func runRealtime[T any](ctx context.Context, rt *realtime, body io.Closer, events *sseReader, out chan<- RealtimeEvent[T]) {
+	defer close(out)
+
+	for {
+		forward(ctx, events, out)
+		body.Close()
+
+		backoff := realtimeMinBackoff
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			var err error
+			body, events, err = rt.connect(ctx)
+			if err == nil {
+				break
+			}
+			backoff = min(backoff*2, realtimeMaxBackoff)
+		}
+	}
+}
+
+// forward decodes stream events onto out until the stream ends or ctx is done.
+func forward[T any](ctx context.Context, events *sseReader, out chan<- RealtimeEvent[T]) {
+	for {
+		ev, err := events.next()
+		if err != nil {
+			return
+		}
+		if ev.name == "PB_CONNECT" {
+			continue
+		}
+
+		var event RealtimeEvent[T]
+		if err := json.Unmarshal([]byte(ev.data), &event); err != nil {
+			continue
+		}
+		event.Topic = ev.name
+
+		select {
+		case out <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+type realtime struct {
+	client *Client
+	topics []string
+	stream *http.Client
+}
+
+// connect opens the event stream and completes the handshake within
+// realtimeHandshakeTimeout. Closing the returned stream ends its request.
+func (rt *realtime) connect(ctx context.Context) (io.Closer, *sseReader, error) {
+	// The deadline must not outlive the handshake, so it cancels the stream's
+	// own context from a timer rather than through context.WithTimeout
+	streamCtx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(realtimeHandshakeTimeout, cancel)
+
+	body, events, err := rt.handshake(streamCtx)
+	if !timer.Stop() {
+		if err == nil {
+			body.Close()
+		}
+		cancel()
+		return nil, nil, &UnavailableError{Err: fmt.Errorf("realtime handshake timed out after %s", realtimeHandshakeTimeout)}
+	}
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return &stream{body: body, cancel: cancel}, events, nil
+}
+
+// handshake opens the event stream, waits for PB_CONNECT and registers the topics for the issued clientId.
+func (rt *realtime) handshake(ctx context.Context) (io.Closer, *sseReader, error) {
+	// The stream outlives the client's timeout; the handshake deadline bounds it instead
+	req, err := rt.client.newRequest(withoutTimeout(ctx), http.MethodGet, "/api/realtime", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := rt.stream.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, nil, fmt.Errorf("failed to connect to realtime: %w", newAPIError(resp))
+	}
+
+	events := newSSEReader(resp.Body)
+	ev, err := events.next()
+	if err != nil {
+		resp.Body.Close()
+		return nil, nil, fmt.Errorf("realtime handshake failed: %w", err)
+	}
+	if ev.name != "PB_CONNECT" {
+		resp.Body.Close()
+		return nil, nil, fmt.Errorf("realtime handshake failed: unexpected %q event", ev.name)
+	}
+
+	var connect struct {
+		ClientID string `json:"clientId"`
+	}
+	if err := json.Unmarshal([]byte(ev.data), &connect); err != nil || connect.ClientID == "" {
+		resp.Body.Close()
+		return nil, nil, errors.New("realtime handshake failed: missing clientId")
+	}
+
+	if err := rt.subscribe(ctx, connect.ClientID); err != nil {
+		resp.Body.Close()
+		return nil, nil, err
+	}
+
+	return resp.Body, events, nil
+}
+
+// stream is an established event stream; closing it also releases its context.
+type stream struct {
+	body   io.Closer
+	cancel context.CancelFunc
+}
+
+func (s *stream) Close() error {
+	s.cancel()
+	return s.body.Close()
+}
+
+func (rt *realtime) subscribe(ctx context.Context, clientID string) error {
+	body := map[string]any{
+		"clientId":      clientID,
+		"subscriptions": rt.topics,
+	}
+
+	req, err := rt.client.newRequest(ctx, http.MethodPost, "/api/realtime", body)
+	if err != nil {
+		return err
+	}
+	return rt.client.do(req, "set realtime subscriptions", nil)
+}
+
+type sseEvent struct {
+	id   string
+	name string
+	data string
+}
+
+// sseReader parses a text/event-stream body into events.
+type sseReader struct {
+	scanner *bufio.Scanner
+}
+
+func newSSEReader(r io.Reader) *sseReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &sseReader{scanner: scanner}
+}
+
+// next blocks until a complete event is read or the stream fails.
+func (r *sseReader) next() (sseEvent, error) {
+	var ev sseEvent
+	var data []string
+
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+
+		if line == "" {
+			if ev.name == "" && len(data) == 0 {
+				continue
+			}
+			ev.data = strings.Join(data, "\n")
+			return ev, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment / keep-alive
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			ev.id = value
+		case "event":
+			ev.name = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return sseEvent{}, err
+	}
+	return sseEvent{}, io.EOF
+}
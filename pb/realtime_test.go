@@ -0,0 +1,267 @@
+package pb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type subscription struct {
+	ClientID      string   `json:"clientId"`
+	Subscriptions []string `json:"subscriptions"`
+}
+
+func TestSubscribe(t *testing.T) {
+	realtimeMinBackoff = 10 * time.Millisecond
+	defer func() { realtimeMinBackoff = 250 * time.Millisecond }()
+
+	var mu sync.Mutex
+	connections := 0
+	ready := map[string]chan struct{}{}
+	subscribed := make(chan subscription, 4)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/realtime", r.URL.Path)
+
+		if r.Method == http.MethodPost {
+			assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+			var sub subscription
+			json.NewDecoder(r.Body).Decode(&sub)
+			subscribed <- sub
+
+			mu.Lock()
+			close(ready[sub.ClientID])
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		mu.Lock()
+		connections++
+		clientID := fmt.Sprintf("client-%d", connections)
+		ready[clientID] = make(chan struct{})
+		subscribedCh := ready[clientID]
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		fmt.Fprintf(w, "id:%s\nevent:PB_CONNECT\ndata:{\"clientId\":%q}\n\n", clientID, clientID)
+		flusher.Flush()
+
+		select {
+		case <-subscribedCh:
+		case <-r.Context().Done():
+			return
+		}
+
+		if clientID == "client-1" {
+			// Deliver one event, then drop the connection to force a reconnect
+			fmt.Fprint(w, "event:posts\ndata:{\"action\":\"create\",\"record\":{\"id\":\"p1\",\"title\":\"First contact\"}}\n\n")
+			flusher.Flush()
+			return
+		}
+
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "event:posts/p1\ndata:{\"action\":\"update\",\"record\":{\"id\":\"p1\",\"title\":\"Second contact\",\"public\":true}}\n\n")
+		flusher.Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := NewClient(server.URL).WithToken("test-token")
+	events, err := Subscribe[Post](ctx, client, "posts", "posts/p1")
+	require.NoError(t, err)
+
+	first := receive(t, events)
+	assert.Equal(t, "posts", first.Topic)
+	assert.Equal(t, "create", first.Action)
+	assert.Equal(t, "First contact", first.Record.Title)
+
+	second := receive(t, events)
+	assert.Equal(t, "posts/p1", second.Topic)
+	assert.Equal(t, "update", second.Action)
+	assert.True(t, second.Record.Public)
+
+	// Both connections registered the same topics under their own clientId
+	sub1, sub2 := <-subscribed, <-subscribed
+	assert.Equal(t, "client-1", sub1.ClientID)
+	assert.Equal(t, "client-2", sub2.ClientID)
+	assert.Equal(t, []string{"posts", "posts/p1"}, sub1.Subscriptions)
+	assert.Equal(t, sub1.Subscriptions, sub2.Subscriptions)
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok, "channel should be closed after cancel")
+	case <-time.After(time.Second):
+		t.Fatal("events channel was not closed")
+	}
+}
+
+func TestSubscribeErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("NoTopics", func(t *testing.T) {
+		_, err := Subscribe[Post](ctx, NewClient("http://localhost"))
+		assert.Error(t, err)
+	})
+
+	t.Run("SubscriptionRejected", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"status": 403, "message": "The current and the previous request authorization don't match.", "data": {}}`))
+				return
+			}
+			fmt.Fprint(w, "event:PB_CONNECT\ndata:{\"clientId\":\"c1\"}\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		_, err := Subscribe[Post](ctx, NewClient(server.URL), "posts")
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+}
+
+func TestSubscribeHandshakeTimeout(t *testing.T) {
+	realtimeHandshakeTimeout = 50 * time.Millisecond
+	realtimeMinBackoff = 10 * time.Millisecond
+	defer func() {
+		realtimeHandshakeTimeout = 10 * time.Second
+		realtimeMinBackoff = 250 * time.Millisecond
+	}()
+	ctx := context.Background()
+
+	// silent accepts the stream but never sends PB_CONNECT
+	silent := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}
+
+	subscribe := func(url string) error {
+		done := make(chan error, 1)
+		go func() {
+			_, err := Subscribe[Post](ctx, NewClient(url, WithCircuitBreaker(BreakerConfig{})), "posts")
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(2 * time.Second):
+			t.Fatal("Subscribe did not give up on the handshake")
+			return nil
+		}
+	}
+
+	t.Run("NoConnectEvent", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(silent))
+		defer server.Close()
+
+		err := subscribe(server.URL)
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.ErrorContains(t, err, "handshake timed out")
+	})
+
+	t.Run("SubscriptionHangs", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				// Drained so the server notices when the client gives up
+				io.Copy(io.Discard, r.Body)
+				<-r.Context().Done()
+				return
+			}
+			fmt.Fprint(w, "event:PB_CONNECT\ndata:{\"clientId\":\"c1\"}\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		assert.ErrorIs(t, subscribe(server.URL), ErrUnavailable)
+	})
+
+	t.Run("ReconnectRetriesSilentServer", func(t *testing.T) {
+		var mu sync.Mutex
+		connections := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			mu.Lock()
+			connections++
+			n := connections
+			mu.Unlock()
+
+			switch n {
+			case 1:
+				// Established, then dropped to force a reconnect
+				fmt.Fprint(w, "event:PB_CONNECT\ndata:{\"clientId\":\"c1\"}\n\n")
+				w.(http.Flusher).Flush()
+			case 2:
+				silent(w, r)
+			default:
+				fmt.Fprint(w, "event:PB_CONNECT\ndata:{\"clientId\":\"c3\"}\n\n")
+				fmt.Fprint(w, "event:posts\ndata:{\"action\":\"create\",\"record\":{\"id\":\"p1\"}}\n\n")
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			}
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		events, err := Subscribe[Post](ctx, NewClient(server.URL), "posts")
+		require.NoError(t, err)
+
+		ev := receive(t, events)
+		assert.Equal(t, "p1", ev.Record.ID)
+		mu.Lock()
+		assert.Equal(t, 3, connections)
+		mu.Unlock()
+	})
+}
+
+func TestSSEReader(t *testing.T) {
+	stream := ": comment\n\nid:1\nevent:posts\ndata:{\"a\":1,\ndata: \"b\":2}\n\nevent:done\ndata:\n\n"
+	reader := newSSEReader(strings.NewReader(stream))
+
+	ev, err := reader.next()
+	require.NoError(t, err)
+	assert.Equal(t, "1", ev.id)
+	assert.Equal(t, "posts", ev.name)
+	assert.Equal(t, "{\"a\":1,\n\"b\":2}", ev.data)
+
+	ev, err = reader.next()
+	require.NoError(t, err)
+	assert.Equal(t, "done", ev.name)
+
+	_, err = reader.next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func receive[T any](t *testing.T, events <-chan RealtimeEvent[T]) RealtimeEvent[T] {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		require.True(t, ok, "events channel closed")
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for realtime event")
+	}
+	return RealtimeEvent[T]{}
+}
@@ -0,0 +1,140 @@
+// Code generated by pbgen from collections.json. DO NOT EDIT.
+
+package pb
+
+import "encoding/json"
+
+// UserCollection is the name of the users collection.
+const UserCollection = "users"
+
+// User is a record of the users collection.
+type User struct {
+	CollectionID    string `json:"collectionId,omitempty"`
+	ID              string `json:"id"`
+	Email           string `json:"email"`
+	EmailVisibility bool   `json:"emailVisibility"`
+	Verified        bool   `json:"verified"`
+	Name            string `json:"name"`
+	Avatar          string `json:"avatar"`
+	Created         string `json:"created"`
+	Updated         string `json:"updated"`
+}
+
+// Field names of the users collection.
+const (
+	UserFieldID              = "id"
+	UserFieldEmail           = "email"
+	UserFieldEmailVisibility = "emailVisibility"
+	UserFieldVerified        = "verified"
+	UserFieldName            = "name"
+	UserFieldAvatar          = "avatar"
+	UserFieldCreated         = "created"
+	UserFieldUpdated         = "updated"
+)
+
+// UserFilter builds filter conditions on the users collection's fields.
+var UserFilter = struct {
+	ID              TextField
+	Email           TextField
+	EmailVisibility BoolField
+	Verified        BoolField
+	Name            TextField
+	Avatar          Field
+	Created         DateField
+	Updated         DateField
+}{
+	ID:              TextField(UserFieldID),
+	Email:           TextField(UserFieldEmail),
+	EmailVisibility: BoolField(UserFieldEmailVisibility),
+	Verified:        BoolField(UserFieldVerified),
+	Name:            TextField(UserFieldName),
+	Avatar:          Field(UserFieldAvatar),
+	Created:         DateField(UserFieldCreated),
+	Updated:         DateField(UserFieldUpdated),
+}
+
+// PostCollection is the name of the posts collection.
+const PostCollection = "posts"
+
+// Post is a record of the posts collection.
+type Post struct {
+	CollectionID string `json:"collectionId,omitempty"`
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	Content      string `json:"content"`
+	Author       string `json:"author"` // Relation to users
+	Public       bool   `json:"public"`
+	Created      string `json:"created"`
+	Updated      string `json:"updated"`
+}
+
+// Field names of the posts collection.
+const (
+	PostFieldID      = "id"
+	PostFieldTitle   = "title"
+	PostFieldContent = "content"
+	PostFieldAuthor  = "author"
+	PostFieldPublic  = "public"
+	PostFieldCreated = "created"
+	PostFieldUpdated = "updated"
+)
+
+// PostFilter builds filter conditions on the posts collection's fields.
+var PostFilter = struct {
+	ID      TextField
+	Title   TextField
+	Content TextField
+	Author  RelationField
+	Public  BoolField
+	Created DateField
+	Updated DateField
+}{
+	ID:      TextField(PostFieldID),
+	Title:   TextField(PostFieldTitle),
+	Content: TextField(PostFieldContent),
+	Author:  RelationField(PostFieldAuthor),
+	Public:  BoolField(PostFieldPublic),
+	Created: DateField(PostFieldCreated),
+	Updated: DateField(PostFieldUpdated),
+}
+
+// WebAuthnCredentialCollection is the name of the webauthn_credentials collection.
+const WebAuthnCredentialCollection = "webauthn_credentials"
+
+// WebAuthnCredential is a record of the webauthn_credentials collection.
+type WebAuthnCredential struct {
+	CollectionID string          `json:"collectionId,omitempty"`
+	ID           string          `json:"id"`
+	User         string          `json:"user"` // Relation to users
+	CredentialID string          `json:"credentialId"`
+	Name         string          `json:"name"`
+	Credential   json.RawMessage `json:"credential"`
+	Created      string          `json:"created"`
+}
+
+// Field names of the webauthn_credentials collection.
+const (
+	WebAuthnCredentialFieldID           = "id"
+	WebAuthnCredentialFieldUser         = "user"
+	WebAuthnCredentialFieldCredentialID = "credentialId"
+	WebAuthnCredentialFieldName         = "name"
+	WebAuthnCredentialFieldCredential   = "credential"
+	WebAuthnCredentialFieldCreated      = "created"
+)
+
+// WebAuthnCredentialFilter builds filter conditions on the webauthn_credentials collection's fields.
+var WebAuthnCredentialFilter = struct {
+	ID           TextField
+	User         RelationField
+	CredentialID TextField
+	Name         TextField
+	Credential   Field
+	Created      DateField
+}{
+	ID:           TextField(WebAuthnCredentialFieldID),
+	User:         RelationField(WebAuthnCredentialFieldUser),
+	CredentialID: TextField(WebAuthnCredentialFieldCredentialID),
+	Name:         TextField(WebAuthnCredentialFieldName),
+	Credential:   Field(WebAuthnCredentialFieldCredential),
+	Created:      DateField(WebAuthnCredentialFieldCreated),
+}
@@ -0,0 +1,262 @@
+package pb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable matches errors caused by PocketBase being unreachable: the
+// connection failed, it answered 502/503/504, or the circuit breaker is open.
+var ErrUnavailable = errors.New("pocketbase unavailable")
+
+// ErrCircuitOpen is returned without contacting PocketBase while the breaker is open.
+// It matches ErrUnavailable.
+var ErrCircuitOpen = &UnavailableError{Err: errors.New("circuit breaker open")}
+
+// UnavailableError reports that PocketBase could not be reached.
+type UnavailableError struct {
+	Err error
+}
+
+func (e *UnavailableError) Error() string {
+	return "pocketbase unreachable: " + e.Err.Error()
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+func (e *UnavailableError) Is(target error) bool {
+	return target == ErrUnavailable
+}
+
+// RetryPolicy controls how idempotent requests (GET, HEAD, OPTIONS, PUT, DELETE)
+// are retried after connection errors and 502/503/504 responses.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first; 1 disables retries
+	BaseDelay   time.Duration // Backoff before the first retry, doubled after each attempt
+	MaxDelay    time.Duration // Upper bound for a single backoff
+}
+
+// DefaultRetryPolicy is used by NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    time.Second,
+}
+
+// backoff returns the full-jitter delay before retry number attempt (starting at 1).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return rand.N(delay) + 1
+}
+
+// BreakerConfig controls the circuit breaker shared by all requests of a client.
+type BreakerConfig struct {
+	Threshold int           // Consecutive failures that open the circuit; 0 disables the breaker
+	Cooldown  time.Duration // How long the circuit stays open before a trial request
+}
+
+// DefaultBreakerConfig is used by NewClient.
+var DefaultBreakerConfig = BreakerConfig{
+	Threshold: 5,
+	Cooldown:  30 * time.Second,
+}
+
+// breaker is a consecutive-failure circuit breaker. Once open it rejects
+// requests until the cooldown passes, then lets a single trial request through:
+// success closes the circuit, failure opens it for another cooldown.
+type breaker struct {
+	config BreakerConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool // A trial request is in flight
+}
+
+func newBreaker(config BreakerConfig) *breaker {
+	return &breaker{config: config, now: time.Now}
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.config.Threshold {
+		return true
+	}
+	if b.trial || b.now().Sub(b.openedAt) < b.config.Cooldown {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// release ends a trial request that was abandoned by its caller without judging PocketBase.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+func (b *breaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if ok {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.config.Threshold {
+		b.openedAt = b.now()
+	}
+}
+
+// resilientTransport retries idempotent requests and fails fast while PocketBase is down.
+type resilientTransport struct {
+	next    http.RoundTripper
+	retry   RetryPolicy
+	breaker *breaker      // nil when disabled
+	timeout time.Duration // Bounds each call, retries and body included; 0 disables it
+}
+
+// noTimeoutKey marks requests that may outlive the client's timeout.
+type noTimeoutKey struct{}
+
+// withoutTimeout exempts requests made with ctx, such as realtime streams, from
+// the client's timeout. They are still bounded by ctx itself.
+func withoutTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noTimeoutKey{}, true)
+}
+
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The timeout is applied here rather than by http.Client, which would merge
+	// it into the caller's context: a PocketBase that hangs must count as a
+	// failure, while a caller that gives up says nothing about its health.
+	caller := req.Context()
+	if t.timeout <= 0 || caller.Value(noTimeoutKey{}) != nil {
+		return t.roundTrip(req, caller)
+	}
+
+	ctx, cancel := context.WithTimeout(caller, t.timeout)
+	resp, err := t.roundTrip(req.WithContext(ctx), caller)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The deadline keeps covering the body until it is closed
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func (t *resilientTransport) roundTrip(req *http.Request, caller context.Context) (*http.Response, error) {
+	attempts := 1
+	if isIdempotent(req.Method) && (req.Body == nil || req.GetBody != nil) {
+		attempts = max(t.retry.MaxAttempts, 1)
+	}
+
+	for attempt := 1; ; attempt++ {
+		if t.breaker != nil && !t.breaker.allow() {
+			return nil, ErrCircuitOpen
+		}
+
+		resp, err := t.next.RoundTrip(req)
+		failed := isUnavailable(resp, err)
+		if t.breaker != nil {
+			// A cancelled caller says nothing about PocketBase's health
+			if caller.Err() != nil {
+				t.breaker.release()
+			} else {
+				t.breaker.record(!failed)
+			}
+		}
+		if !failed || attempt >= attempts || req.Context().Err() != nil {
+			if err != nil && failed && caller.Err() == nil {
+				err = &UnavailableError{Err: err}
+			}
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(req.Context(), t.retry.backoff(attempt)); err != nil {
+			if caller.Err() == nil {
+				// The client's timeout ran out between attempts
+				return nil, &UnavailableError{Err: err}
+			}
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// cancelBody releases a response's timeout once its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isUnavailable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return isUnavailableStatus(resp.StatusCode)
+}
+
+func isUnavailableStatus(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,217 @@
+package pb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastRetries = WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+
+func TestRetryIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// PocketBase is flaky: every first attempt of a request fails
+		if calls.Add(1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"items": []Post{{ID: "post-1"}}, "id": "post-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, fastRetries).WithToken("user-token")
+	ctx := context.Background()
+
+	t.Run("GetIsRetried", func(t *testing.T) {
+		calls.Store(0)
+		posts, err := client.ListPostsContext(ctx)
+		require.NoError(t, err)
+		assert.Len(t, posts, 1)
+		assert.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("PostIsNot", func(t *testing.T) {
+		calls.Store(0)
+		err := client.CreatePostContext(ctx, "Title", "Content", false)
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("DeleteIsRetried", func(t *testing.T) {
+		calls.Store(0)
+		err := client.DeletePostContext(ctx, "post-1")
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, calls.Load())
+	})
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var calls, status atomic.Int32
+	status.Store(http.StatusBadGateway)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, fastRetries, WithCircuitBreaker(BreakerConfig{}))
+	_, err := client.ListPostsContext(context.Background())
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.EqualValues(t, 3, calls.Load())
+
+	// Client errors are not retried
+	calls.Store(0)
+	status.Store(http.StatusNotFound)
+	_, err = client.ListPostsContext(context.Background())
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client := NewClient(server.URL, fastRetries)
+	_, err := client.ListPostsContext(context.Background())
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	var unavailable *UnavailableError
+	assert.ErrorAs(t, err, &unavailable)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"items": []Post{}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL,
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithCircuitBreaker(BreakerConfig{Threshold: 2, Cooldown: time.Minute}),
+	)
+	transport := client.HTTPClient.Transport.(*resilientTransport)
+	now := time.Now()
+	transport.breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Two failures open the circuit
+	for range 2 {
+		_, err := client.ListPostsContext(ctx)
+		assert.ErrorIs(t, err, ErrUnavailable)
+	}
+	assert.EqualValues(t, 2, calls.Load())
+
+	// While open, requests fail fast without reaching PocketBase, for every token
+	_, err := client.WithToken("other-user").ListPostsContext(ctx)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.EqualValues(t, 2, calls.Load())
+
+	// After the cooldown a failed trial re-opens it
+	now = now.Add(time.Minute)
+	_, err = client.ListPostsContext(ctx)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.EqualValues(t, 3, calls.Load())
+	_, err = client.ListPostsContext(ctx)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	// A successful trial closes it
+	healthy.Store(true)
+	now = now.Add(time.Minute)
+	_, err = client.ListPostsContext(ctx)
+	assert.NoError(t, err)
+	_, err = client.ListPostsContext(ctx)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, calls.Load())
+}
+
+func TestTimeout(t *testing.T) {
+	var calls atomic.Int32
+	// PocketBase accepts the connection but never answers
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL,
+		WithTimeout(50*time.Millisecond),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithCircuitBreaker(BreakerConfig{Threshold: 2, Cooldown: time.Minute}),
+	)
+	ctx := context.Background()
+
+	t.Run("CallerCancelDoesNotCount", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err := client.ListPostsContext(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, ErrUnavailable)
+		assert.Zero(t, client.HTTPClient.Transport.(*resilientTransport).breaker.failures)
+	})
+
+	t.Run("ClientTimeoutOpensCircuit", func(t *testing.T) {
+		calls.Store(0)
+		for range 2 {
+			_, err := client.ListPostsContext(ctx)
+			assert.ErrorIs(t, err, ErrUnavailable)
+			var unavailable *UnavailableError
+			assert.ErrorAs(t, err, &unavailable)
+		}
+		assert.EqualValues(t, 2, calls.Load())
+
+		_, err := client.ListPostsContext(ctx)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.EqualValues(t, 2, calls.Load())
+	})
+}
+
+func TestTimeoutCoversBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Headers arrive in time, the body never finishes
+		w.Write([]byte(`{"items": [`))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithTimeout(50*time.Millisecond))
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.ListPostsContext(context.Background())
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("reading the body ignored the client timeout")
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for attempt := 1; attempt <= 5; attempt++ {
+		limit := min(policy.BaseDelay<<(attempt-1), policy.MaxDelay)
+		for range 20 {
+			delay := policy.backoff(attempt)
+			assert.Greater(t, delay, time.Duration(0))
+			assert.LessOrEqual(t, delay, limit)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package pb
+
+//go:generate go run ../cmd/pbgen -schema collections.json -out records_gen.go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// CollectionSchema is a collection definition as served by PocketBase's
+// /api/collections endpoints, which require a superuser client.
+// A nil rule restricts the action to superusers; an empty one allows everyone.
+type CollectionSchema struct {
+	ID         string        `json:"id,omitempty"`
+	Name       string        `json:"name"`
+	Type       string        `json:"type"` // "base", "auth" or "view"
+	System     bool          `json:"system,omitempty"`
+	Fields     []SchemaField `json:"fields"`
+	Indexes    []string      `json:"indexes"`
+	ListRule   *string       `json:"listRule"`
+	ViewRule   *string       `json:"viewRule"`
+	CreateRule *string       `json:"createRule"`
+	UpdateRule *string       `json:"updateRule"`
+	DeleteRule *string       `json:"deleteRule"`
+}
+
+// Field returns the field with the given name, or nil.
+func (s *CollectionSchema) Field(name string) *SchemaField {
+	for i := range s.Fields {
+		if s.Fields[i].Name == name {
+			return &s.Fields[i]
+		}
+	}
+	return nil
+}
+
+// SchemaField is one field of a collection. Settings specific to the field
+// type (max, maxSelect, collectionId, cascadeDelete...) live in Options and
+// are sent back untouched, so updating a schema never drops them.
+type SchemaField struct {
+	ID       string
+	Name     string
+	Type     string // "text", "bool", "relation", "json", "autodate"...
+	Required bool
+	System   bool
+	Hidden   bool
+	Options  map[string]any
+}
+
+// Rule returns a pointer to rule, for CollectionSchema's rule fields.
+func Rule(rule string) *string {
+	return &rule
+}
+
+func (f SchemaField) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any, len(f.Options)+6)
+	for key, value := range f.Options {
+		out[key] = value
+	}
+	if f.ID != "" {
+		out["id"] = f.ID
+	}
+	out["name"] = f.Name
+	out["type"] = f.Type
+	out["required"] = f.Required
+	out["system"] = f.System
+	out["hidden"] = f.Hidden
+	return json.Marshal(out)
+}
+
+func (f *SchemaField) UnmarshalJSON(data []byte) error {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	f.ID, _ = raw["id"].(string)
+	f.Name, _ = raw["name"].(string)
+	f.Type, _ = raw["type"].(string)
+	f.Required, _ = raw["required"].(bool)
+	f.System, _ = raw["system"].(bool)
+	f.Hidden, _ = raw["hidden"].(bool)
+	for _, key := range []string{"id", "name", "type", "required", "system", "hidden"} {
+		delete(raw, key)
+	}
+	f.Options = raw
+	return nil
+}
+
+func collectionSchemaPath(nameOrID string) string {
+	return "/api/collections/" + url.PathEscape(nameOrID)
+}
+
+// CollectionSchemas lists every collection definition.
+func (c *Client) CollectionSchemas(ctx context.Context) ([]CollectionSchema, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/api/collections?perPage=500", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ListResult[CollectionSchema]
+	if err := c.do(req, "list collections", &result); err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// CollectionSchema fetches the definition of a collection by name or id.
+// Unknown collections fail with ErrNotFound.
+func (c *Client) CollectionSchema(ctx context.Context, nameOrID string) (*CollectionSchema, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, collectionSchemaPath(nameOrID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema CollectionSchema
+	if err := c.do(req, "fetch collection "+nameOrID, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// CreateCollectionSchema creates a collection and returns its stored definition.
+func (c *Client) CreateCollectionSchema(ctx context.Context, schema CollectionSchema) (*CollectionSchema, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/api/collections", schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var created CollectionSchema
+	if err := c.do(req, "create collection "+schema.Name, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateCollectionSchema replaces the fields, indexes and rules of a collection.
+// Fields missing from schema.Fields are deleted, along with their data.
+func (c *Client) UpdateCollectionSchema(ctx context.Context, nameOrID string, schema CollectionSchema) (*CollectionSchema, error) {
+	req, err := c.newRequest(ctx, http.MethodPatch, collectionSchemaPath(nameOrID), schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated CollectionSchema
+	if err := c.do(req, "update collection "+nameOrID, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
@@ -0,0 +1,95 @@
+package pb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// superuserRenewWindow re-authenticates superuser tokens this close to expiry.
+const superuserRenewWindow = 5 * time.Minute
+
+// Superuser hands out clients authenticated as a PocketBase superuser, for
+// server-side work no user token can authorize (e.g. minting sessions).
+// The token is obtained on first use and renewed before it expires.
+type Superuser struct {
+	client   *Client
+	email    string
+	password string
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewSuperuser returns a Superuser that signs in with the given credentials through client.
+func NewSuperuser(client *Client, email, password string) *Superuser {
+	return &Superuser{client: client, email: email, password: password}
+}
+
+// Client returns a client carrying a valid superuser token.
+func (s *Superuser) Client(ctx context.Context) (*Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" {
+		claims, err := ParseToken(s.token)
+		if err == nil && !claims.ExpiresWithin(time.Now(), superuserRenewWindow) {
+			return s.client.WithToken(s.token), nil
+		}
+	}
+
+	token, err := s.client.AuthAsSuperuser(ctx, s.email, s.password)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return s.client.WithToken(token), nil
+}
+
+// AuthAsSuperuser signs in to the _superusers collection and returns the token.
+func (c *Client) AuthAsSuperuser(ctx context.Context, email, password string) (string, error) {
+	body := map[string]any{
+		"identity": email,
+		"password": password,
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/api/collections/_superusers/auth-with-password", body)
+	if err != nil {
+		return "", err
+	}
+
+	var authResp struct {
+		Token string `json:"token"`
+	}
+	if err := c.do(req, "authenticate superuser", &authResp); err != nil {
+		return "", err
+	}
+	if authResp.Token == "" {
+		return "", errors.New("superuser auth returned no token")
+	}
+	return authResp.Token, nil
+}
+
+// Impersonate issues an auth token for the record without its credentials.
+// It requires a superuser client. A zero duration uses the collection's default.
+func (c *Client) Impersonate(ctx context.Context, collection, recordID string, duration time.Duration) (string, *User, error) {
+	body := map[string]any{}
+	if duration > 0 {
+		body["duration"] = int(duration.Seconds())
+	}
+
+	path := "/api/collections/" + url.PathEscape(collection) + "/impersonate/" + url.PathEscape(recordID)
+	req, err := c.newRequest(ctx, http.MethodPost, path, body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var authResp authResponse
+	if err := c.do(req, "impersonate "+collection+" record", &authResp); err != nil {
+		return "", nil, err
+	}
+	return authResp.Token, &authResp.Record, nil
+}
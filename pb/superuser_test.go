@@ -0,0 +1,85 @@
+package pb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func superuserToken(exp time.Time) string {
+	payload := fmt.Sprintf(`{"id":"su-1","type":"auth","collectionId":"pbc_3142635823","exp":%d}`, exp.Unix())
+	return "header." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".signature"
+}
+
+func TestSuperuserImpersonate(t *testing.T) {
+	logins := 0
+	tokenExp := time.Now().Add(time.Hour)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/api/collections/_superusers/auth-with-password":
+			logins++
+			if body["identity"] != "admin@fleet.com" || body["password"] != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"status": 400, "message": "Failed to authenticate.", "data": {}}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"token": superuserToken(tokenExp)})
+		case "/api/collections/users/impersonate/user-123":
+			assert.Equal(t, "Bearer "+superuserToken(tokenExp), r.Header.Get("Authorization"))
+			assert.Equal(t, float64(600), body["duration"])
+			json.NewEncoder(w).Encode(map[string]any{
+				"token":  "impersonated-token",
+				"record": map[string]any{"id": "user-123", "email": "pilot@fleet.com"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	superuser := NewSuperuser(NewClient(server.URL), "admin@fleet.com", "secret")
+
+	t.Run("ReusesToken", func(t *testing.T) {
+		admin, err := superuser.Client(ctx)
+		require.NoError(t, err)
+
+		token, user, err := admin.Impersonate(ctx, "users", "user-123", 10*time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "impersonated-token", token)
+		assert.Equal(t, "pilot@fleet.com", user.Email)
+
+		_, err = superuser.Client(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, logins)
+	})
+
+	t.Run("RenewsBeforeExpiry", func(t *testing.T) {
+		logins = 0
+		tokenExp = time.Now().Add(time.Minute)
+		superuser := NewSuperuser(NewClient(server.URL), "admin@fleet.com", "secret")
+
+		_, err := superuser.Client(ctx)
+		require.NoError(t, err)
+		_, err = superuser.Client(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, logins)
+	})
+
+	t.Run("BadCredentials", func(t *testing.T) {
+		_, err := NewSuperuser(NewClient(server.URL), "admin@fleet.com", "wrong").Client(ctx)
+		assert.ErrorIs(t, err, ErrBadRequest)
+	})
+}
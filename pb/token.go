@@ -0,0 +1,64 @@
+package pb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// TokenClaims are the unverified claims of a PocketBase auth token.
+// PocketBase still verifies the signature on every API call; these are only
+// used to route requests and to decide when a token needs refreshing.
+type TokenClaims struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	CollectionID string `json:"collectionId"`
+	Exp          int64  `json:"exp"`
+	// Refreshable is false for impersonation tokens, which PocketBase
+	// refuses to refresh. Older tokens omit the claim.
+	Refreshable *bool `json:"refreshable"`
+}
+
+// ParseToken decodes the payload of a PocketBase JWT without verifying it.
+func ParseToken(token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.New("malformed token payload")
+	}
+
+	var claims TokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errors.New("malformed token claims")
+	}
+	return &claims, nil
+}
+
+// ExpiresAt returns the token expiry, or the zero time when the token has no exp claim.
+func (t *TokenClaims) ExpiresAt() time.Time {
+	if t.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(t.Exp, 0)
+}
+
+// Expired reports whether the token is past its expiry at now.
+func (t *TokenClaims) Expired(now time.Time) bool {
+	return t.Exp != 0 && !now.Before(t.ExpiresAt())
+}
+
+// ExpiresWithin reports whether the token expires less than d after now.
+func (t *TokenClaims) ExpiresWithin(now time.Time, d time.Duration) bool {
+	return t.Exp != 0 && t.ExpiresAt().Sub(now) < d
+}
+
+// CanRefresh reports whether PocketBase will accept the token for an auth refresh.
+func (t *TokenClaims) CanRefresh() bool {
+	return t.Refreshable == nil || *t.Refreshable
+}
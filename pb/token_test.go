@@ -0,0 +1,40 @@
+package pb
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseToken(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"id":"user-123","type":"auth","collectionId":"_pb_users_auth_","exp":1768478400}`))
+
+	claims, err := ParseToken("header." + payload + ".signature")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-123", claims.ID)
+	assert.Equal(t, "auth", claims.Type)
+	assert.Equal(t, time.Unix(1768478400, 0), claims.ExpiresAt())
+
+	exp := claims.ExpiresAt()
+	assert.False(t, claims.Expired(exp.Add(-time.Second)))
+	assert.True(t, claims.Expired(exp))
+	assert.True(t, claims.ExpiresWithin(exp.Add(-time.Minute), time.Hour))
+	assert.False(t, claims.ExpiresWithin(exp.Add(-2*time.Hour), time.Hour))
+
+	_, err = ParseToken("not-a-jwt")
+	assert.Error(t, err)
+	_, err = ParseToken("header.!!!.signature")
+	assert.Error(t, err)
+
+	noExp, err := ParseToken("header." + base64.RawURLEncoding.EncodeToString([]byte(`{"id":"x"}`)) + ".signature")
+	assert.NoError(t, err)
+	assert.False(t, noExp.Expired(time.Now()))
+	assert.False(t, noExp.ExpiresWithin(time.Now(), time.Hour))
+	assert.True(t, noExp.CanRefresh(), "tokens without the claim are refreshable")
+
+	impersonated, err := ParseToken("header." + base64.RawURLEncoding.EncodeToString([]byte(`{"id":"x","refreshable":false}`)) + ".signature")
+	assert.NoError(t, err)
+	assert.False(t, impersonated.CanRefresh())
+}
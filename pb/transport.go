@@ -0,0 +1,161 @@
+package pb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Middleware wraps the transport of a Client to observe or decorate every
+// PocketBase call: logging, metrics, request IDs, extra headers.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts an ordinary function to http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// WithMiddleware installs middlewares around the client's transport. The first
+// one sees each request first. They run once per call: retries and the circuit
+// breaker sit beneath them, so a call that fails fast is still observed.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(cfg *clientConfig) { cfg.middlewares = append(cfg.middlewares, middlewares...) }
+}
+
+// chain wraps transport so that middlewares[0] is the outermost.
+func chain(transport http.RoundTripper, middlewares []Middleware) http.RoundTripper {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		transport = middlewares[i](transport)
+	}
+	return transport
+}
+
+// Call describes a finished PocketBase call.
+type Call struct {
+	Method     string
+	Path       string
+	Collection string // Collection the call targets, "" for non-collection endpoints
+	Status     int    // 0 when no response was received
+	Latency    time.Duration
+	Err        error
+}
+
+// Observe calls fn after every PocketBase call, e.g. to record metrics.
+func Observe(fn func(ctx context.Context, call Call)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			call := Call{
+				Method:     req.Method,
+				Path:       req.URL.Path,
+				Collection: collectionFromPath(req.URL.Path),
+				Latency:    time.Since(start),
+				Err:        err,
+			}
+			if resp != nil {
+				call.Status = resp.StatusCode
+			}
+			fn(req.Context(), call)
+			return resp, err
+		})
+	}
+}
+
+// Logging logs every PocketBase call with its request ID, status and latency.
+func Logging(logger *log.Logger) Middleware {
+	return Observe(func(ctx context.Context, call Call) {
+		target := call.Collection
+		if target == "" {
+			target = call.Path
+		}
+		outcome := http.StatusText(call.Status)
+		if call.Err != nil {
+			outcome = call.Err.Error()
+		}
+		logger.Printf("pocketbase | %s | %d %s | %s %s | %s",
+			RequestIDFromContext(ctx), call.Status, outcome, call.Method, target, call.Latency.Round(time.Microsecond))
+	})
+}
+
+// RequestIDHeader carries the request ID to PocketBase (visible in its logs).
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context whose PocketBase calls carry id.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored by ContextWithRequestID, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestID sends the context's request ID in the X-Request-ID header,
+// generating one for calls made outside a request. Install it before Logging
+// or Observe so they see generated IDs too.
+func RequestID() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(RequestIDHeader) != "" {
+				return next.RoundTrip(req)
+			}
+
+			ctx := req.Context()
+			id := RequestIDFromContext(ctx)
+			if id == "" {
+				id = newRequestID()
+				ctx = ContextWithRequestID(ctx, id)
+			}
+			req = req.Clone(ctx)
+			req.Header.Set(RequestIDHeader, id)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Headers adds fixed headers to every PocketBase call, keeping any the request already set.
+func Headers(headers http.Header) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			for key, values := range headers {
+				if req.Header.Get(key) != "" {
+					continue
+				}
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// collectionFromPath extracts the collection name from PocketBase API paths
+// such as /api/collections/posts/records or /api/files/posts/<id>/<file>.
+func collectionFromPath(path string) string {
+	for _, prefix := range []string{"/api/collections/", "/api/files/"} {
+		if rest, ok := strings.CutPrefix(path, prefix); ok {
+			name, _, _ := strings.Cut(rest, "/")
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package pb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientMiddleware(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		if r.URL.Path == "/api/collections/posts/records/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"items": []Post{}})
+	}))
+	defer server.Close()
+
+	var calls []Call
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	var logs bytes.Buffer
+
+	client := NewClient(server.URL, WithMiddleware(
+		trace("first"),
+		RequestID(),
+		Headers(http.Header{"X-Client": {"gosmic"}}),
+		Observe(func(ctx context.Context, call Call) { calls = append(calls, call) }),
+		Logging(log.New(&logs, "", 0)),
+		trace("last"),
+	))
+	ctx := ContextWithRequestID(context.Background(), "req-42")
+
+	t.Run("DecoratesAndObservesCalls", func(t *testing.T) {
+		_, err := client.Posts().List(ctx, ListOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"first", "last"}, order)
+		assert.Equal(t, "req-42", headers.Get("X-Request-ID"))
+		assert.Equal(t, "gosmic", headers.Get("X-Client"))
+
+		require.Len(t, calls, 1)
+		assert.Equal(t, http.MethodGet, calls[0].Method)
+		assert.Equal(t, "posts", calls[0].Collection)
+		assert.Equal(t, http.StatusOK, calls[0].Status)
+		assert.Positive(t, calls[0].Latency)
+		assert.Contains(t, logs.String(), "pocketbase | req-42 | 200 OK | GET posts |")
+	})
+
+	t.Run("ObservesFailures", func(t *testing.T) {
+		calls = nil
+		_, err := client.Posts().Get(ctx, "missing")
+		assert.ErrorIs(t, err, ErrNotFound)
+		require.Len(t, calls, 1)
+		assert.Equal(t, http.StatusNotFound, calls[0].Status)
+	})
+
+	t.Run("GeneratesRequestIDs", func(t *testing.T) {
+		logs.Reset()
+		_, err := client.WithToken("user-token").Posts().List(context.Background(), ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, headers.Get("X-Request-ID"), 16)
+		assert.Contains(t, logs.String(), "pocketbase | "+headers.Get("X-Request-ID")+" |")
+	})
+}
+
+func TestCollectionFromPath(t *testing.T) {
+	assert.Equal(t, "posts", collectionFromPath("/api/collections/posts/records"))
+	assert.Equal(t, "users", collectionFromPath("/api/collections/users/auth-with-password"))
+	assert.Equal(t, "users", collectionFromPath("/api/files/users/u1/avatar.png"))
+	assert.Equal(t, "", collectionFromPath("/api/realtime"))
+}
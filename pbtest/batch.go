@@ -0,0 +1,85 @@
+package pbtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+)
+
+// batch serves /api/batch: each request runs through the regular record
+// endpoints with the caller's token, and the first failure rolls every
+// collection back. Calls made concurrently with a batch are not isolated from it.
+func (s *Server) batch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Requests []struct {
+			Method string          `json:"method"`
+			URL    string          `json:"url"`
+			Body   json.RawMessage `json:"body"`
+		} `json:"requests"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Requests) == 0 {
+		writeError(w, http.StatusBadRequest, "Failed to load the submitted data due to invalid formatting.", nil)
+		return
+	}
+
+	restore := s.snapshot()
+	results := make([]map[string]any, 0, len(body.Requests))
+	for i, sub := range body.Requests {
+		req, err := http.NewRequestWithContext(r.Context(), sub.Method, sub.URL, bytes.NewReader(sub.Body))
+		if err != nil {
+			restore()
+			writeError(w, http.StatusBadRequest, "Failed to load the submitted data due to invalid formatting.", nil)
+			return
+		}
+		req.Header.Set("Authorization", r.Header.Get("Authorization"))
+
+		rec := httptest.NewRecorder()
+		s.handler.ServeHTTP(rec, req)
+		if rec.Code >= http.StatusBadRequest {
+			restore()
+			writeJSON(w, http.StatusBadRequest, map[string]any{
+				"status":  http.StatusBadRequest,
+				"message": "Batch transaction failed.",
+				"data": map[string]any{"requests": map[string]any{
+					strconv.Itoa(i): map[string]any{
+						"code":     "batch_request_failed",
+						"message":  "Batch request failed.",
+						"response": json.RawMessage(rec.Body.Bytes()),
+					},
+				}},
+			})
+			return
+		}
+
+		var result any
+		json.Unmarshal(rec.Body.Bytes(), &result)
+		results = append(results, map[string]any{"status": rec.Code, "body": result})
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// snapshot saves every collection and password and returns a func restoring them.
+func (s *Server) snapshot() (restore func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := map[string][]map[string]any{}
+	for name, c := range s.collections {
+		// Records are replaced, never modified, so copying the slice is enough
+		records[name] = slices.Clone(c.records)
+	}
+	passwords := maps.Clone(s.passwords)
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for name, c := range s.collections {
+			c.records = records[name]
+		}
+		s.passwords = passwords
+	}
+}
@@ -0,0 +1,85 @@
+package pbtest
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/pb"
+)
+
+// fakeT captures failures of a Recorder under test and runs its cleanups on demand.
+type fakeT struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeT) Helper()           {}
+func (f *fakeT) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+func (f *fakeT) Failed() bool      { return len(f.errors) > 0 }
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) finish() {
+	for _, fn := range f.cleanups {
+		fn()
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	server := seeded(t)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	record := &fakeT{TB: t}
+	recorder := NewRecorder(record, path, Record)
+	client := pb.NewClient(server.URL, pb.WithTransport(recorder)).WithToken(server.Token("kirk"))
+	_, err := client.Posts().List(ctx, pb.ListOptions{Sort: "-created"})
+	require.NoError(t, err)
+	_, err = client.Posts().Get(ctx, "p4")
+	require.ErrorIs(t, err, pb.ErrNotFound)
+	record.finish()
+	require.Empty(t, record.errors)
+
+	server.Close() // Replays must not need PocketBase
+
+	t.Run("Replay", func(t *testing.T) {
+		replay := &fakeT{TB: t}
+		client := pb.NewClient(replayURL, pb.WithTransport(NewRecorder(replay, path, Replay)))
+
+		result, err := client.Posts().List(ctx, pb.ListOptions{Sort: "-created"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Shore leave", "Captain's log", "First contact"}, titles(result.Items))
+		_, err = client.Posts().Get(ctx, "p4")
+		assert.ErrorIs(t, err, pb.ErrNotFound)
+
+		replay.finish()
+		assert.Empty(t, replay.errors)
+	})
+
+	t.Run("UnexpectedRequest", func(t *testing.T) {
+		replay := &fakeT{TB: t}
+		client := pb.NewClient(replayURL, pb.WithTransport(NewRecorder(replay, path, Replay)), pb.WithRetryPolicy(pb.RetryPolicy{MaxAttempts: 1}))
+
+		_, err := client.Posts().List(ctx, pb.ListOptions{Sort: "created"})
+		assert.Error(t, err)
+		require.Len(t, replay.errors, 1)
+		assert.Contains(t, replay.errors[0], `got query "sort=created", recorded "sort=-created"`)
+	})
+
+	t.Run("UnplayedInteractions", func(t *testing.T) {
+		replay := &fakeT{TB: t}
+		client := pb.NewClient(replayURL, pb.WithTransport(NewRecorder(replay, path, Replay)))
+
+		_, err := client.Posts().List(ctx, pb.ListOptions{Sort: "-created"})
+		require.NoError(t, err)
+		replay.finish()
+		require.Len(t, replay.errors, 1)
+		assert.Contains(t, replay.errors[0], "1 recorded interaction(s)")
+	})
+}
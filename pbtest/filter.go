@@ -0,0 +1,405 @@
+package pbtest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This file implements the subset of PocketBase's filter syntax used by the
+// app and by API rules: comparisons (=, !=, >, >=, <, <=, ~, !~) between fields,
+// literals and @request.auth / @request.body values, combined with && and ||
+// and grouped with parentheses.
+
+// env resolves identifiers while evaluating a filter.
+type env struct {
+	record map[string]any // Fields of the record being tested
+	auth   map[string]any // Authenticated user record, nil for guests
+	body   map[string]any // Request body, for create/update rules
+}
+
+func (e env) lookup(name string) (any, error) {
+	switch {
+	case strings.HasPrefix(name, "@request.auth."):
+		if e.auth == nil {
+			return "", nil
+		}
+		return fieldValue(e.auth, strings.TrimPrefix(name, "@request.auth.")), nil
+	case strings.HasPrefix(name, "@request.body."):
+		return fieldValue(e.body, strings.TrimPrefix(name, "@request.body.")), nil
+	case strings.HasPrefix(name, "@"):
+		return nil, fmt.Errorf("unsupported identifier %s", name)
+	}
+	if _, ok := e.record[name]; !ok {
+		return nil, fmt.Errorf("unknown field %s", name)
+	}
+	return e.record[name], nil
+}
+
+// fieldValue returns a field of m, with PocketBase's zero value ("") for missing fields.
+func fieldValue(m map[string]any, name string) any {
+	if value, ok := m[name]; ok {
+		return value
+	}
+	return ""
+}
+
+// expr is a parsed filter expression.
+type expr interface {
+	eval(e env) (bool, error)
+}
+
+type logical struct {
+	and         bool
+	left, right expr
+}
+
+func (l logical) eval(e env) (bool, error) {
+	left, err := l.left.eval(e)
+	if err != nil {
+		return false, err
+	}
+	if l.and != left {
+		// false && x, true || x
+		return left, nil
+	}
+	return l.right.eval(e)
+}
+
+type comparison struct {
+	op          string
+	left, right operand
+}
+
+type operand struct {
+	ident   string // Set for field and @request identifiers
+	literal any
+}
+
+func (o operand) value(e env) (any, error) {
+	if o.ident != "" {
+		return e.lookup(o.ident)
+	}
+	return o.literal, nil
+}
+
+func (c comparison) eval(e env) (bool, error) {
+	left, err := c.left.value(e)
+	if err != nil {
+		return false, err
+	}
+	right, err := c.right.value(e)
+	if err != nil {
+		return false, err
+	}
+
+	switch c.op {
+	case "=":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "~":
+		return like(left, right), nil
+	case "!~":
+		return !like(left, right), nil
+	}
+
+	order := compare(left, right)
+	switch c.op {
+	case ">":
+		return order > 0, nil
+	case ">=":
+		return order >= 0, nil
+	case "<":
+		return order < 0, nil
+	case "<=":
+		return order <= 0, nil
+	}
+	return false, fmt.Errorf("unsupported operator %s", c.op)
+}
+
+func normalize(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case int:
+		return float64(v)
+	}
+	return v
+}
+
+func equal(a, b any) bool {
+	a, b = normalize(a), normalize(b)
+	// PocketBase compares booleans with their stored 0/1 and strings as text
+	if ab, ok := a.(bool); ok {
+		if bs, ok := b.(string); ok {
+			return bs == strconv.FormatBool(ab)
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func compare(a, b any) int {
+	a, b = normalize(a), normalize(b)
+	af, aok := a.(float64)
+	bf, bok := b.(float64)
+	if aok && bok {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	// Datetimes are stored as sortable text
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// like implements ~: a case-insensitive LIKE where the pattern is wrapped in %
+// unless it already contains one. \%, \_ and \\ match literally.
+func like(value, pattern any) bool {
+	p := fmt.Sprint(normalize(pattern))
+	if !strings.Contains(strings.ReplaceAll(p, `\%`, ""), "%") {
+		p = "%" + p + "%"
+	}
+
+	var re strings.Builder
+	re.WriteString("(?is)^")
+	for i := 0; i < len(p); i++ {
+		switch ch := p[i]; {
+		case ch == '\\' && i+1 < len(p) && (p[i+1] == '%' || p[i+1] == '_' || p[i+1] == '\\'):
+			re.WriteString(regexp.QuoteMeta(string(p[i+1])))
+			i++
+		case ch == '%':
+			re.WriteString(".*")
+		case ch == '_':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String()).MatchString(fmt.Sprint(normalize(value)))
+}
+
+// parseFilter parses a filter expression; an empty filter matches everything.
+func parseFilter(src string) (expr, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, nil
+	}
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return e, nil
+}
+
+// matches evaluates a parsed filter; a nil filter matches everything.
+func matches(filter expr, e env) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+	return filter.eval(e)
+}
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+	tokLogical
+	tokOpen
+	tokClose
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var operators = []string{"!=", ">=", "<=", "!~", "=", ">", "<", "~"}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		ch := src[i]
+		switch {
+		case unicode.IsSpace(rune(ch)):
+			i++
+		case ch == '(':
+			tokens = append(tokens, token{tokOpen, "("})
+			i++
+		case ch == ')':
+			tokens = append(tokens, token{tokClose, ")"})
+			i++
+		case strings.HasPrefix(src[i:], "&&"), strings.HasPrefix(src[i:], "||"):
+			tokens = append(tokens, token{tokLogical, src[i : i+2]})
+			i += 2
+		case ch == '"' || ch == '\'':
+			// Like PocketBase, the string ends at the first unescaped quote
+			// and \<quote> is the only escape; other backslashes are kept
+			j := i + 1
+			for ; j < len(src) && (src[j] != ch || src[j-1] == '\\'); j++ {
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			text := strings.ReplaceAll(src[i+1:j], "\\"+string(ch), string(ch))
+			tokens = append(tokens, token{tokString, text})
+			i = j + 1
+		case ch == '-' || (ch >= '0' && ch <= '9'):
+			j := i + 1
+			for j < len(src) && (src[j] == '.' || (src[j] >= '0' && src[j] <= '9')) {
+				j++
+			}
+			tokens = append(tokens, token{tokNumber, src[i:j]})
+			i = j
+		case ch == '@' || ch == '_' || unicode.IsLetter(rune(ch)):
+			j := i + 1
+			for j < len(src) && (src[j] == '.' || src[j] == '_' || src[j] == '@' ||
+				unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			tokens = append(tokens, token{tokIdent, src[i:j]})
+			i = j
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, token{tokOp, op})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q", ch)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() *token {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *parser) next() (token, error) {
+	if p.pos >= len(p.tokens) {
+		return token{}, fmt.Errorf("unexpected end of filter")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *parser) or() (expr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t != nil && t.kind == tokLogical && t.text == "||"; t = p.peek() {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (expr, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t != nil && t.kind == tokLogical && t.text == "&&"; t = p.peek() {
+		p.pos++
+		right, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) primary() (expr, error) {
+	if t := p.peek(); t != nil && t.kind == tokOpen {
+		p.pos++
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t, err := p.next(); err != nil || t.kind != tokClose {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return e, nil
+	}
+
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if op.kind != tokOp {
+		return nil, fmt.Errorf("expected an operator, got %q", op.text)
+	}
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return comparison{op: op.text, left: left, right: right}, nil
+}
+
+func (p *parser) operand() (operand, error) {
+	t, err := p.next()
+	if err != nil {
+		return operand{}, err
+	}
+	switch t.kind {
+	case tokString:
+		return operand{literal: t.text}, nil
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return operand{}, fmt.Errorf("invalid number %q", t.text)
+		}
+		return operand{literal: n}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return operand{literal: true}, nil
+		case "false":
+			return operand{literal: false}, nil
+		case "null":
+			return operand{literal: ""}, nil
+		}
+		return operand{ident: t.text}, nil
+	}
+	return operand{}, fmt.Errorf("unexpected %q", t.text)
+}
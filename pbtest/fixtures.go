@@ -0,0 +1,144 @@
+package pbtest
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/torresposso/gosmic/pb"
+)
+
+// User is a users record to seed, with the password it signs in with.
+type User struct {
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+	Verified bool   `json:"verified"`
+}
+
+// Fixtures are records loaded into the server before a test runs.
+type Fixtures struct {
+	Users []User    `json:"users"`
+	Posts []pb.Post `json:"posts"`
+}
+
+// Seed inserts fixtures directly, bypassing API rules and validation.
+// Users come first so posts can reference them.
+func (s *Server) Seed(fixtures Fixtures) {
+	for _, user := range fixtures.Users {
+		s.AddUser(user)
+	}
+	for _, post := range fixtures.Posts {
+		s.AddPost(post)
+	}
+}
+
+// SeedFile seeds fixtures from a JSON file shaped like Fixtures, failing the test on error.
+func (s *Server) SeedFile(t testing.TB, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("pbtest: reading fixtures: %v", err)
+	}
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		t.Fatalf("pbtest: parsing %s: %v", path, err)
+	}
+	s.Seed(fixtures)
+}
+
+// AddUser inserts a user and returns it as the client sees it. A missing ID is generated.
+func (s *Server) AddUser(user User) *pb.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.ID == "" {
+		user.ID = newID()
+	}
+	now := s.timestamp()
+	users := s.collections["users"]
+	users.records = append(users.records, map[string]any{
+		"id":              user.ID,
+		"collectionId":    users.id,
+		"collectionName":  users.name,
+		"email":           user.Email,
+		"name":            user.Name,
+		"avatar":          "",
+		"verified":        user.Verified,
+		"emailVisibility": false,
+		"created":         now,
+		"updated":         now,
+	})
+	s.passwords[user.ID] = user.Password
+	return toUser(users.records[len(users.records)-1])
+}
+
+// AddPost inserts a post and returns it with its ID and timestamps filled in.
+// Fixed Created/Updated values are kept so tests can order posts deterministically.
+func (s *Server) AddPost(post pb.Post) pb.Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if post.ID == "" {
+		post.ID = newID()
+	}
+	now := s.timestamp()
+	if post.Created == "" {
+		post.Created = now
+	}
+	if post.Updated == "" {
+		post.Updated = post.Created
+	}
+	posts := s.collections["posts"]
+	posts.records = append(posts.records, map[string]any{
+		"id":             post.ID,
+		"collectionId":   posts.id,
+		"collectionName": posts.name,
+		"title":          post.Title,
+		"content":        post.Content,
+		"author":         post.Author,
+		"public":         post.Public,
+		"created":        post.Created,
+		"updated":        post.Updated,
+	})
+	return post
+}
+
+// User returns the stored user with id, or nil.
+func (s *Server) User(id string) *pb.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, record := s.collections["users"].find(id)
+	if record == nil {
+		return nil
+	}
+	return toUser(record)
+}
+
+// Posts returns every stored post in insertion order, regardless of rules.
+func (s *Server) Posts() []pb.Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var posts []pb.Post
+	for _, record := range s.collections["posts"].records {
+		var post pb.Post
+		convert(record, &post)
+		posts = append(posts, post)
+	}
+	return posts
+}
+
+func toUser(record map[string]any) *pb.User {
+	var user pb.User
+	convert(record, &user)
+	return &user
+}
+
+// convert decodes a stored record into a pb type through its JSON form.
+func convert(record map[string]any, out any) {
+	data, _ := json.Marshal(record)
+	json.Unmarshal(data, out)
+}
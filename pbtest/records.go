@@ -0,0 +1,393 @@
+package pbtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/torresposso/gosmic/pb"
+)
+
+const (
+	maxPerPage     = 1000
+	defaultPerPage = 30
+)
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/collections/users/auth-with-password", s.authWithPassword)
+	mux.HandleFunc("POST /api/collections/users/auth-refresh", s.authRefresh)
+	mux.HandleFunc("POST /api/collections/users/request-verification", s.requestVerification)
+	mux.HandleFunc("POST /api/collections/users/confirm-verification", s.confirmVerification)
+	mux.HandleFunc("GET /api/collections/{collection}/records", s.withCollection(s.listRecords))
+	mux.HandleFunc("POST /api/collections/{collection}/records", s.withCollection(s.createRecord))
+	mux.HandleFunc("PUT /api/collections/{collection}/records", s.withCollection(s.upsertRecord))
+	mux.HandleFunc("GET /api/collections/{collection}/records/{id}", s.withCollection(s.viewRecord))
+	mux.HandleFunc("PATCH /api/collections/{collection}/records/{id}", s.withCollection(s.updateRecord))
+	mux.HandleFunc("DELETE /api/collections/{collection}/records/{id}", s.withCollection(s.deleteRecord))
+	mux.HandleFunc("POST /api/batch", s.batch)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "The requested resource wasn't found.", nil)
+	})
+	return mux
+}
+
+type recordHandler func(w http.ResponseWriter, r *http.Request, c *collection, auth map[string]any)
+
+// withCollection resolves the collection and the caller while holding the lock.
+func (s *Server) withCollection(next recordHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		c, ok := s.collections[r.PathValue("collection")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Missing collection context.", nil)
+			return
+		}
+		next(w, r, c, s.authUser(r))
+	}
+}
+
+func (s *Server) authWithPassword(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Identity string `json:"identity"`
+		Password string `json:"password"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.collections["users"].records {
+		if user["email"] == body.Identity && s.passwords[user["id"].(string)] == body.Password && body.Password != "" {
+			writeJSON(w, http.StatusOK, map[string]any{"token": s.issueToken(user["id"].(string)), "record": user})
+			return
+		}
+	}
+	writeError(w, http.StatusBadRequest, "Failed to authenticate.", nil)
+}
+
+func (s *Server) authRefresh(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := s.authUser(r)
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "The request requires valid record authorization token.", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"token": s.issueToken(user["id"].(string)), "record": user})
+}
+
+func (s *Server) listRecords(w http.ResponseWriter, r *http.Request, c *collection, auth map[string]any) {
+	if c.rules.List == Locked {
+		writeError(w, http.StatusForbidden, "Only superusers can perform this action.", nil)
+		return
+	}
+	rule := mustParseRule(c.rules.List)
+
+	query := r.URL.Query()
+	filter, err := parseFilter(query.Get("filter"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Something went wrong while processing your request. Invalid filter parameters.", nil)
+		return
+	}
+
+	items := []map[string]any{}
+	for _, record := range c.records {
+		e := env{record: record, auth: auth}
+		allowed, err := matches(rule, e)
+		if err != nil {
+			panic("pbtest: " + err.Error())
+		}
+		ok, err := matches(filter, e)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Something went wrong while processing your request. Invalid filter parameters.", nil)
+			return
+		}
+		if allowed && ok {
+			items = append(items, record)
+		}
+	}
+
+	if err := sortRecords(items, query.Get("sort"), c); err != nil {
+		writeError(w, http.StatusBadRequest, "Something went wrong while processing your request. Invalid sort parameters.", nil)
+		return
+	}
+
+	page := max(atoi(query.Get("page"), 1), 1)
+	perPage := min(max(atoi(query.Get("perPage"), defaultPerPage), 1), maxPerPage)
+	totalItems := len(items)
+	totalPages := (totalItems + perPage - 1) / perPage
+	if query.Get("skipTotal") == "true" {
+		totalItems, totalPages = -1, -1
+	}
+
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+	writeJSON(w, http.StatusOK, map[string]any{
+		"page":       page,
+		"perPage":    perPage,
+		"totalItems": totalItems,
+		"totalPages": totalPages,
+		"items":      items[start:end],
+	})
+}
+
+// sortRecords orders records by a PocketBase sort expression such as "-created,title".
+func sortRecords(records []map[string]any, sort string, c *collection) error {
+	if sort == "" {
+		return nil
+	}
+
+	type key struct {
+		field string
+		desc  bool
+	}
+	var keys []key
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		k := key{field: strings.TrimPrefix(strings.TrimPrefix(part, "-"), "+"), desc: strings.HasPrefix(part, "-")}
+		if _, ok := c.fields[k.field]; !ok && k.field != "id" && k.field != "created" && k.field != "updated" {
+			return errInvalidSort
+		}
+		keys = append(keys, k)
+	}
+
+	slices.SortStableFunc(records, func(a, b map[string]any) int {
+		for _, k := range keys {
+			order := compare(sortValue(a[k.field]), sortValue(b[k.field]))
+			if k.desc {
+				order = -order
+			}
+			if order != 0 {
+				return order
+			}
+		}
+		return 0
+	})
+	return nil
+}
+
+var errInvalidSort = errors.New("invalid sort field")
+
+// sortValue orders booleans like SQLite's 0/1.
+func sortValue(v any) any {
+	if b, ok := v.(bool); ok {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	return v
+}
+
+func (s *Server) viewRecord(w http.ResponseWriter, r *http.Request, c *collection, auth map[string]any) {
+	_, record := c.find(r.PathValue("id"))
+	if record == nil || !s.allowed(c.rules.View, env{record: record, auth: auth}) {
+		writeError(w, http.StatusNotFound, "The requested resource wasn't found.", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, record)
+}
+
+func (s *Server) createRecord(w http.ResponseWriter, r *http.Request, c *collection, auth map[string]any) {
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to load the submitted data due to invalid formatting.", nil)
+		return
+	}
+
+	now := s.timestamp()
+	record := map[string]any{
+		"id":             newID(),
+		"collectionId":   c.id,
+		"collectionName": c.name,
+		"created":        now,
+		"updated":        now,
+	}
+	for name, typ := range c.fields {
+		record[name] = coerce(body[name], typ)
+	}
+	if id, ok := body["id"].(string); ok && id != "" {
+		record["id"] = id
+	}
+
+	errs := s.validate(c, record, body, true)
+	if _, existing := c.find(record["id"].(string)); existing != nil {
+		errs["id"] = fieldError("validation_not_unique", "Value must be unique.")
+	}
+	if len(errs) > 0 {
+		writeError(w, http.StatusBadRequest, "Failed to create record.", errs)
+		return
+	}
+	if !s.allowed(c.rules.Create, env{record: record, auth: auth, body: body}) {
+		writeError(w, http.StatusBadRequest, "Failed to create record.", nil)
+		return
+	}
+
+	if c.name == "users" {
+		s.passwords[record["id"].(string)] = body["password"].(string)
+	}
+	c.records = append(c.records, record)
+	writeJSON(w, http.StatusOK, record)
+}
+
+func (s *Server) updateRecord(w http.ResponseWriter, r *http.Request, c *collection, auth map[string]any) {
+	i, existing := c.find(r.PathValue("id"))
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to load the submitted data due to invalid formatting.", nil)
+		return
+	}
+	if existing == nil || !s.allowed(c.rules.Update, env{record: existing, auth: auth, body: body}) {
+		writeError(w, http.StatusNotFound, "The requested resource wasn't found.", nil)
+		return
+	}
+
+	record := make(map[string]any, len(existing))
+	for name, value := range existing {
+		record[name] = value
+	}
+	for name, typ := range c.fields {
+		if value, ok := body[name]; ok {
+			record[name] = coerce(value, typ)
+		}
+	}
+	record["updated"] = s.timestamp()
+
+	if errs := s.validate(c, record, body, false); len(errs) > 0 {
+		writeError(w, http.StatusBadRequest, "Failed to update record.", errs)
+		return
+	}
+	c.records[i] = record
+	writeJSON(w, http.StatusOK, record)
+}
+
+// upsertRecord updates the record whose id is in the body, or creates it.
+func (s *Server) upsertRecord(w http.ResponseWriter, r *http.Request, c *collection, auth map[string]any) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var fields struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(body, &fields)
+	if _, existing := c.find(fields.ID); fields.ID != "" && existing != nil {
+		r.SetPathValue("id", fields.ID)
+		s.updateRecord(w, r, c, auth)
+		return
+	}
+	s.createRecord(w, r, c, auth)
+}
+
+func (s *Server) deleteRecord(w http.ResponseWriter, r *http.Request, c *collection, auth map[string]any) {
+	i, record := c.find(r.PathValue("id"))
+	if record == nil || !s.allowed(c.rules.Delete, env{record: record, auth: auth}) {
+		writeError(w, http.StatusNotFound, "The requested resource wasn't found.", nil)
+		return
+	}
+	c.records = slices.Delete(c.records, i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// allowed evaluates an API rule. Locked rules deny everyone since the fake has no superusers.
+func (s *Server) allowed(rule string, e env) bool {
+	if rule == Locked {
+		return false
+	}
+	ok, err := matches(mustParseRule(rule), e)
+	if err != nil {
+		panic("pbtest: " + err.Error())
+	}
+	return ok
+}
+
+// validate checks the record the way the users and posts schemas would.
+func (s *Server) validate(c *collection, record, body map[string]any, creating bool) map[string]pb.FieldError {
+	errs := map[string]pb.FieldError{}
+
+	switch c.name {
+	case "users":
+		// PocketBase validates in stages and stops at the first failing one:
+		// the password confirmation, then the fields, then unique indexes.
+		if creating {
+			password, _ := body["password"].(string)
+			if confirm, _ := body["passwordConfirm"].(string); confirm != password {
+				errs["passwordConfirm"] = fieldError("validation_values_mismatch", "Values don't match.")
+				return errs
+			}
+			switch {
+			case password == "":
+				errs["password"] = fieldError("validation_required", "Cannot be blank.")
+			case len(password) < 8:
+				errs["password"] = fieldError("validation_min_text_constraint", "Must be at least 8 character(s).")
+			}
+		}
+		email, _ := record["email"].(string)
+		switch {
+		case email == "":
+			errs["email"] = fieldError("validation_required", "Cannot be blank.")
+		case !strings.Contains(email, "@"):
+			errs["email"] = fieldError("validation_is_email", "Must be a valid email address.")
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+		for _, other := range c.records {
+			if other["id"] != record["id"] && strings.EqualFold(other["email"].(string), email) {
+				errs["email"] = fieldError("validation_not_unique", "Value must be unique.")
+			}
+		}
+	case "posts":
+		if record["title"] == "" {
+			errs["title"] = fieldError("validation_required", "Cannot be blank.")
+		}
+		author, _ := record["author"].(string)
+		if author == "" {
+			errs["author"] = fieldError("validation_required", "Cannot be blank.")
+		} else if _, user := s.collections["users"].find(author); user == nil {
+			errs["author"] = fieldError("validation_missing_rel_records", "Failed to find all relation records with the provided ids.")
+		}
+	}
+	return errs
+}
+
+// coerce converts a submitted value to the field's type, as PocketBase does.
+func coerce(value any, typ fieldType) any {
+	switch typ {
+	case boolField:
+		switch v := value.(type) {
+		case bool:
+			return v
+		case string:
+			b, _ := strconv.ParseBool(v)
+			return b
+		case float64:
+			return v != 0
+		}
+		return false
+	default:
+		switch v := value.(type) {
+		case nil:
+			return ""
+		case string:
+			return v
+		}
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+}
+
+func atoi(s string, fallback int) int {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	return fallback
+}
@@ -0,0 +1,240 @@
+// Package pbtest runs an in-process stand-in for PocketBase so tests can drive
+// the real pb.Client end to end.
+//
+// The fake serves the users and posts collections: records CRUD, users
+// auth-with-password and auth-refresh, list filter/sort/pagination and the
+// collections' API rules, written in PocketBase's own filter syntax, and
+// transactional /api/batch writes. Email verification links are delivered
+// over SMTP to a MailServer. Any other endpoint answers 404.
+package pbtest
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/torresposso/gosmic/pb"
+)
+
+// Rules are a collection's API rules in PocketBase filter syntax.
+// An empty rule lets everyone through, Locked only superusers (i.e. nobody here).
+type Rules struct {
+	List   string
+	View   string
+	Create string
+	Update string
+	Delete string
+}
+
+// Locked is the rule of actions reserved to superusers.
+const Locked = "locked"
+
+// DefaultUserRules let users see and edit only their own record; anyone may register.
+var DefaultUserRules = Rules{
+	List:   "id = @request.auth.id",
+	View:   "id = @request.auth.id",
+	Create: "",
+	Update: "id = @request.auth.id",
+	Delete: "id = @request.auth.id",
+}
+
+// DefaultPostRules publish public posts to everyone and keep the rest private
+// to their author. Only verified users may publish.
+var DefaultPostRules = Rules{
+	List:   "public = true || author = @request.auth.id",
+	View:   "public = true || author = @request.auth.id",
+	Create: `@request.auth.id != "" && @request.body.author = @request.auth.id && (@request.body.public != true || @request.auth.verified = true)`,
+	Update: "author = @request.auth.id && (@request.body.public != true || @request.auth.verified = true)",
+	Delete: "author = @request.auth.id",
+}
+
+// TokenTTL is how long issued auth tokens are valid.
+const TokenTTL = time.Hour
+
+// dateLayout is PocketBase's datetime format.
+const dateLayout = "2006-01-02 15:04:05.000Z"
+
+// Server is a fake PocketBase. Create it with NewServer.
+type Server struct {
+	URL string
+
+	server  *httptest.Server
+	handler http.Handler
+	now     func() time.Time
+
+	mu          sync.Mutex
+	collections map[string]*collection
+	passwords   map[string]string // User ID -> password
+	tokens      map[string]string // Token -> user ID
+
+	verifications map[string]string // Verification token -> user ID
+	smtpAddr      string            // Set by SendMailTo
+	appURL        string
+}
+
+type collection struct {
+	id      string
+	name    string
+	fields  map[string]fieldType // Writable fields besides id, created and updated
+	rules   Rules
+	records []map[string]any // In insertion order
+}
+
+type fieldType int
+
+const (
+	textField fieldType = iota
+	boolField
+)
+
+func (c *collection) find(id string) (int, map[string]any) {
+	for i, record := range c.records {
+		if record["id"] == id {
+			return i, record
+		}
+	}
+	return -1, nil
+}
+
+// NewServer starts a fake PocketBase with empty users and posts collections.
+// It is closed when the test ends.
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		now: time.Now,
+		collections: map[string]*collection{
+			"users": {
+				id:    "_pb_users_auth_",
+				name:  "users",
+				rules: DefaultUserRules,
+				fields: map[string]fieldType{
+					"email": textField, "name": textField, "avatar": textField,
+					"verified": boolField, "emailVisibility": boolField,
+				},
+			},
+			"posts": {
+				id:    "pbc_posts",
+				name:  "posts",
+				rules: DefaultPostRules,
+				fields: map[string]fieldType{
+					"title": textField, "content": textField, "author": textField, "public": boolField,
+				},
+			},
+		},
+		passwords:     map[string]string{},
+		tokens:        map[string]string{},
+		verifications: map[string]string{},
+	}
+	s.handler = s.routes()
+	s.server = httptest.NewServer(s.handler)
+	s.URL = s.server.URL
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// Close shuts the server down; later calls fail as if PocketBase were unreachable.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SetRules replaces the API rules of the users or posts collection.
+func (s *Server) SetRules(collection string, rules Rules) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collections[collection].rules = rules
+}
+
+// Client returns a pb.Client for the fake, without retries so failures surface immediately.
+func (s *Server) Client() *pb.Client {
+	return pb.NewClient(s.URL, pb.WithRetryPolicy(pb.RetryPolicy{MaxAttempts: 1}))
+}
+
+// AuthClient returns a client signed in as userID, with its AuthRecord populated
+// the way middleware.AuthMiddleware does.
+func (s *Server) AuthClient(userID string) *pb.Client {
+	client := s.Client().WithToken(s.Token(userID))
+	client.AuthRecord = s.User(userID)
+	return client
+}
+
+// Token issues a valid auth token for userID.
+func (s *Server) Token(userID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issueToken(userID)
+}
+
+// issueToken must be called with s.mu held.
+func (s *Server) issueToken(userID string) string {
+	claims, _ := json.Marshal(map[string]any{
+		"id":           userID,
+		"type":         "auth",
+		"collectionId": s.collections["users"].id,
+		"exp":          s.now().Add(TokenTTL).Unix(),
+	})
+	token := "pbtest." + base64.RawURLEncoding.EncodeToString(claims) + "." + newID()
+	s.tokens[token] = userID
+	return token
+}
+
+// authUser returns the user a request is authenticated as, or nil for guests
+// and unknown tokens. It must be called with s.mu held.
+func (s *Server) authUser(r *http.Request) map[string]any {
+	token := r.Header.Get("Authorization")
+	if len(token) > 7 && token[:7] == "Bearer " {
+		token = token[7:]
+	}
+	userID, ok := s.tokens[token]
+	if !ok {
+		return nil
+	}
+	_, user := s.collections["users"].find(userID)
+	return user
+}
+
+func (s *Server) timestamp() string {
+	return s.now().UTC().Format(dateLayout)
+}
+
+// newID returns a random 15 character id like PocketBase's.
+func newID() string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, 15)
+	rand.Read(b)
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b)
+}
+
+// writeJSON sends v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError sends PocketBase's error envelope.
+func writeError(w http.ResponseWriter, status int, message string, data map[string]pb.FieldError) {
+	if data == nil {
+		data = map[string]pb.FieldError{}
+	}
+	writeJSON(w, status, map[string]any{"status": status, "message": message, "data": data})
+}
+
+func fieldError(code, message string) pb.FieldError {
+	return pb.FieldError{Code: code, Message: message}
+}
+
+func mustParseRule(rule string) expr {
+	filter, err := parseFilter(rule)
+	if err != nil {
+		panic(fmt.Sprintf("pbtest: invalid rule %q: %v", rule, err))
+	}
+	return filter
+}
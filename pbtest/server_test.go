@@ -0,0 +1,271 @@
+package pbtest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/pb"
+)
+
+func seeded(t *testing.T) *Server {
+	s := NewServer(t)
+	s.SeedFile(t, "testdata/fixtures.json")
+	return s
+}
+
+func TestAuthWithPassword(t *testing.T) {
+	s := seeded(t)
+	ctx := context.Background()
+
+	token, user, err := s.Client().AuthWithPasswordContext(ctx, "kirk@enterprise.test", "password123")
+	require.NoError(t, err)
+	assert.Equal(t, "kirk", user.ID)
+	assert.Equal(t, "James Kirk", user.Name)
+
+	claims, err := pb.ParseToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "kirk", claims.ID)
+
+	_, _, err = s.Client().AuthWithPasswordContext(ctx, "kirk@enterprise.test", "wrong")
+	assert.ErrorIs(t, err, pb.ErrBadRequest)
+
+	refreshed, user, err := s.Client().WithToken(token).AuthRefresh(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, token, refreshed)
+	assert.Equal(t, "kirk", user.ID)
+
+	_, _, err = s.Client().WithToken("forged").AuthRefresh(ctx)
+	assert.ErrorIs(t, err, pb.ErrUnauthorized)
+}
+
+func TestListRules(t *testing.T) {
+	s := seeded(t)
+	ctx := context.Background()
+
+	guest, err := s.Client().Posts().FullList(ctx, pb.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"First contact", "Shore leave"}, titles(guest))
+
+	kirk, err := s.AuthClient("kirk").Posts().FullList(ctx, pb.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"First contact", "Captain's log", "Shore leave"}, titles(kirk))
+
+	_, err = s.Client().Posts().Get(ctx, "p2")
+	assert.ErrorIs(t, err, pb.ErrNotFound, "private posts are hidden from others")
+
+	s.SetRules("posts", Rules{List: Locked})
+	_, err = s.AuthClient("kirk").Posts().List(ctx, pb.ListOptions{})
+	assert.ErrorIs(t, err, pb.ErrForbidden)
+}
+
+func TestListFilterSortAndPagination(t *testing.T) {
+	s := seeded(t)
+	client := s.AuthClient("kirk")
+	ctx := context.Background()
+
+	t.Run("Filter", func(t *testing.T) {
+		result, err := client.Posts().List(ctx, pb.ListOptions{Filter: `title ~ "log" || (public = false && content ~ 'LOG')`})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Captain's log"}, titles(result.Items))
+
+		result, err = client.Posts().List(ctx, pb.ListOptions{Filter: `created >= "2024-02-01 00:00:00.000Z" && public = true`})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Shore leave"}, titles(result.Items))
+
+		result, err = client.Posts().List(ctx, pb.ListOptions{Filter: `title ~ "100\% sure"`})
+		require.NoError(t, err)
+		assert.Empty(t, result.Items)
+	})
+
+	t.Run("InvalidFilter", func(t *testing.T) {
+		for _, filter := range []string{`title ~`, `secret = 1`, `title = "unterminated`, `(public = true`} {
+			_, err := client.Posts().List(ctx, pb.ListOptions{Filter: filter})
+			assert.ErrorIs(t, err, pb.ErrBadRequest, filter)
+		}
+	})
+
+	t.Run("Sort", func(t *testing.T) {
+		result, err := client.Posts().List(ctx, pb.ListOptions{Sort: "-created"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Shore leave", "Captain's log", "First contact"}, titles(result.Items))
+
+		result, err = client.Posts().List(ctx, pb.ListOptions{Sort: "-public,title"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"First contact", "Shore leave", "Captain's log"}, titles(result.Items))
+
+		_, err = client.Posts().List(ctx, pb.ListOptions{Sort: "secret"})
+		assert.ErrorIs(t, err, pb.ErrBadRequest)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		result, err := client.Posts().List(ctx, pb.ListOptions{Sort: "created", Page: 2, PerPage: 2})
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Page)
+		assert.Equal(t, 3, result.TotalItems)
+		assert.Equal(t, 2, result.TotalPages)
+		assert.Equal(t, []string{"Shore leave"}, titles(result.Items))
+		assert.False(t, result.HasMore())
+
+		result, err = client.Posts().List(ctx, pb.ListOptions{PerPage: 2, SkipTotal: true})
+		require.NoError(t, err)
+		assert.Equal(t, -1, result.TotalItems)
+		assert.Len(t, result.Items, 2)
+		assert.True(t, result.HasMore())
+	})
+}
+
+func TestCreateUpdateDelete(t *testing.T) {
+	s := seeded(t)
+	kirk := s.AuthClient("kirk")
+	spock := s.AuthClient("spock")
+	ctx := context.Background()
+
+	t.Run("CreateRequiresAuthorAsCaller", func(t *testing.T) {
+		_, err := s.Client().Posts().Create(ctx, map[string]any{"title": "Stowaway", "author": "kirk"})
+		assert.ErrorIs(t, err, pb.ErrBadRequest)
+
+		_, err = spock.Posts().Create(ctx, map[string]any{"title": "Impersonation", "author": "kirk"})
+		assert.ErrorIs(t, err, pb.ErrBadRequest)
+
+		require.NoError(t, kirk.CreatePostContext(ctx, "Warp speed", "Engage", true))
+		assert.Len(t, s.Posts(), 5)
+	})
+
+	t.Run("CreateValidatesFields", func(t *testing.T) {
+		err := kirk.CreatePostContext(ctx, "", "No title", false)
+		assert.Equal(t, map[string]string{"title": "Cannot be blank."}, pb.FieldErrors(err))
+	})
+
+	t.Run("UpdateAndDeleteOnlyOwnPosts", func(t *testing.T) {
+		err := spock.UpdatePostContext(ctx, "p2", map[string]any{"public": true})
+		assert.ErrorIs(t, err, pb.ErrNotFound)
+
+		require.NoError(t, kirk.UpdatePostContext(ctx, "p2", map[string]any{"public": true}))
+		post, err := s.Client().Posts().Get(ctx, "p2")
+		require.NoError(t, err)
+		assert.True(t, post.Public)
+		assert.NotEqual(t, post.Created, post.Updated)
+
+		assert.ErrorIs(t, spock.DeletePostContext(ctx, "p2"), pb.ErrNotFound)
+		require.NoError(t, kirk.DeletePostContext(ctx, "p2"))
+		_, err = kirk.Posts().Get(ctx, "p2")
+		assert.ErrorIs(t, err, pb.ErrNotFound)
+	})
+}
+
+func TestRegisterValidation(t *testing.T) {
+	s := seeded(t)
+	ctx := context.Background()
+
+	// Each stage only reports once the previous one passes, like PocketBase
+	_, err := s.Client().Users().Create(ctx, map[string]any{
+		"email": "kirk@enterprise.test", "password": "short", "passwordConfirm": "other",
+	})
+	assert.Equal(t, map[string]string{"passwordConfirm": "Values don't match."}, pb.FieldErrors(err))
+
+	_, err = s.Client().Users().Create(ctx, map[string]any{
+		"email": "kirk@enterprise.test", "password": "short", "passwordConfirm": "short",
+	})
+	assert.Equal(t, map[string]string{"password": "Must be at least 8 character(s)."}, pb.FieldErrors(err))
+
+	_, err = s.Client().Users().Create(ctx, map[string]any{
+		"email": "kirk@enterprise.test", "password": "hailing123", "passwordConfirm": "hailing123",
+	})
+	assert.Equal(t, map[string]string{"email": "Value must be unique."}, pb.FieldErrors(err))
+
+	user, err := s.Client().Users().Create(ctx, map[string]any{
+		"email": "uhura@enterprise.test", "name": "Nyota Uhura", "password": "hailing123", "passwordConfirm": "hailing123",
+	})
+	require.NoError(t, err)
+	assert.False(t, user.Verified)
+
+	_, _, err = s.Client().AuthWithPasswordContext(ctx, "uhura@enterprise.test", "hailing123")
+	assert.NoError(t, err)
+}
+
+func TestVerificationEmail(t *testing.T) {
+	s := NewServer(t)
+	s.AddUser(User{ID: "scotty", Email: "scotty@enterprise.test", Password: "password123"})
+	mail := NewMailServer(t)
+	s.SendMailTo(mail, "http://gosmic.test/")
+	ctx := context.Background()
+
+	require.NoError(t, s.Client().RequestVerification(ctx, "nobody@enterprise.test"), "unknown addresses are not revealed")
+	require.NoError(t, s.Client().RequestVerification(ctx, "scotty@enterprise.test"))
+	inbox := mail.Inbox("scotty@enterprise.test")
+	require.Len(t, inbox, 1)
+	assert.Equal(t, "noreply@pbtest.local", inbox[0].From)
+	_, token, ok := strings.Cut(inbox[0].Body, "http://gosmic.test/verify/")
+	require.True(t, ok, inbox[0].Body)
+	token = strings.TrimSpace(token)
+
+	claims, err := pb.ParseToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "scotty", claims.ID)
+
+	assert.ErrorIs(t, s.Client().ConfirmVerification(ctx, "forged"), pb.ErrBadRequest)
+	require.NoError(t, s.Client().ConfirmVerification(ctx, token))
+	assert.True(t, s.User("scotty").Verified)
+
+	require.NoError(t, s.Client().RequestVerification(ctx, "scotty@enterprise.test"))
+	assert.Len(t, mail.Inbox("scotty@enterprise.test"), 1, "verified users get no more links")
+}
+
+func TestClosedServerIsUnavailable(t *testing.T) {
+	s := NewServer(t)
+	s.Close()
+
+	_, err := s.Client().Posts().List(context.Background(), pb.ListOptions{})
+	assert.ErrorIs(t, err, pb.ErrUnavailable)
+}
+
+func titles(posts []pb.Post) []string {
+	var out []string
+	for _, post := range posts {
+		out = append(out, post.Title)
+	}
+	return out
+}
+
+func TestBatch(t *testing.T) {
+	s := seeded(t)
+	kirk := s.AuthClient("kirk")
+	ctx := context.Background()
+
+	t.Run("RollsBackOnFailure", func(t *testing.T) {
+		_, err := kirk.Batch().
+			Create("posts", map[string]any{"title": "Doomed", "author": "kirk"}).
+			Delete("posts", "p1").
+			Delete("posts", "p3"). // Spock's post
+			Send(ctx)
+
+		var batchErr *pb.BatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, 2, batchErr.Index)
+		assert.ErrorIs(t, err, pb.ErrNotFound)
+		assert.Equal(t, []string{"First contact", "Captain's log", "Shore leave", "Private log"}, titles(s.Posts()))
+	})
+
+	t.Run("AppliesEverything", func(t *testing.T) {
+		results, err := kirk.Batch().
+			Create("posts", map[string]any{"title": "Warp speed", "author": "kirk"}).
+			Update("posts", "p2", map[string]any{"public": true}).
+			Upsert("posts", map[string]any{"id": "p1", "title": "First contact (revised)", "author": "kirk", "public": true}).
+			Upsert("posts", map[string]any{"id": "p9", "title": "Upserted", "author": "kirk"}).
+			Delete("posts", "p1").
+			Send(ctx)
+		require.NoError(t, err)
+		require.Len(t, results, 5)
+
+		var created pb.Post
+		require.NoError(t, results[0].Decode(&created))
+		assert.Equal(t, "Warp speed", created.Title)
+		assert.Equal(t, http.StatusNoContent, results[4].Status)
+		assert.Equal(t, []string{"Captain's log", "Shore leave", "Private log", "Warp speed", "Upserted"}, titles(s.Posts()))
+		assert.True(t, s.Posts()[0].Public)
+	})
+}
@@ -0,0 +1,160 @@
+package pbtest
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// MailServer is a local SMTP server that keeps every message it receives, a
+// stand-in for the mail server PocketBase delivers its emails to. Create it
+// with NewMailServer.
+type MailServer struct {
+	Addr string // host:port to send to
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// Message is an email received by a MailServer.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Body    string
+}
+
+// NewMailServer starts an SMTP server on a free local port. It is closed
+// when the test ends.
+func NewMailServer(t testing.TB) *MailServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("pbtest: starting SMTP server: %v", err)
+	}
+	m := &MailServer{Addr: listener.Addr().String(), listener: listener}
+	m.wg.Add(1)
+	go m.serve()
+	t.Cleanup(m.Close)
+	return m
+}
+
+// Close stops accepting mail and waits for open sessions to end.
+func (m *MailServer) Close() {
+	m.listener.Close()
+	m.wg.Wait()
+}
+
+// Inbox returns the messages delivered to address, oldest first.
+func (m *MailServer) Inbox(address string) []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var inbox []Message
+	for _, msg := range m.messages {
+		for _, to := range msg.To {
+			if strings.EqualFold(to, address) {
+				inbox = append(inbox, msg)
+				break
+			}
+		}
+	}
+	return inbox
+}
+
+func (m *MailServer) serve() {
+	defer m.wg.Done()
+	for {
+		conn, err := m.listener.Accept()
+		if err != nil {
+			return
+		}
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			defer conn.Close()
+			m.session(textproto.NewConn(conn))
+		}()
+	}
+}
+
+// session speaks just enough SMTP for net/smtp.SendMail: no extensions, no
+// authentication, no TLS.
+func (m *MailServer) session(conn *textproto.Conn) {
+	reply := func(code int, text string) bool {
+		return conn.PrintfLine("%d %s", code, text) == nil
+	}
+	if !reply(220, "pbtest ESMTP") {
+		return
+	}
+
+	var from string
+	var to []string
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO", "EHLO":
+			reply(250, "pbtest")
+		case "MAIL":
+			from, to = address(arg), nil
+			reply(250, "OK")
+		case "RCPT":
+			to = append(to, address(arg))
+			reply(250, "OK")
+		case "DATA":
+			if from == "" || len(to) == 0 {
+				reply(503, "Need MAIL and RCPT first")
+				continue
+			}
+			reply(354, "End data with <CR><LF>.<CR><LF>")
+			data, err := io.ReadAll(conn.DotReader())
+			if err != nil {
+				return
+			}
+			m.receive(from, to, data)
+			from, to = "", nil
+			reply(250, "OK")
+		case "RSET":
+			from, to = "", nil
+			reply(250, "OK")
+		case "NOOP":
+			reply(250, "OK")
+		case "QUIT":
+			reply(221, "Bye")
+			return
+		default:
+			reply(502, "Command not implemented")
+		}
+	}
+}
+
+func (m *MailServer) receive(from string, to []string, data []byte) {
+	msg := Message{From: from, To: to, Body: string(data)}
+	if parsed, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(string(data)))); err == nil {
+		msg.Subject = parsed.Header.Get("Subject")
+		if body, err := io.ReadAll(parsed.Body); err == nil {
+			msg.Body = string(body)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+}
+
+// address extracts the mailbox from a "FROM:<a@b>" or "TO:<a@b>" argument.
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	addr, _, _ = strings.Cut(strings.TrimSpace(addr), " ")
+	return strings.Trim(addr, "<>")
+}
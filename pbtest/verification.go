@@ -0,0 +1,91 @@
+package pbtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/smtp"
+	"strings"
+)
+
+// SendMailTo makes the fake email verification links through m, the way
+// PocketBase does once its SMTP settings point at a mail server. Links start
+// with appURL followed by /verify/{TOKEN}, the template Gosmic expects.
+func (s *Server) SendMailTo(m *MailServer, appURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.smtpAddr = m.Addr
+	s.appURL = strings.TrimSuffix(appURL, "/")
+}
+
+// requestVerification emails a verification link to an unverified user. Like
+// PocketBase it answers 204 whether or not the address is registered.
+func (s *Server) requestVerification(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Email string `json:"email"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	s.mu.Lock()
+	var user map[string]any
+	for _, record := range s.collections["users"].records {
+		if record["email"] == body.Email && body.Email != "" {
+			user = record
+		}
+	}
+	if user == nil || user["verified"] == true || s.smtpAddr == "" {
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	token := s.issueVerificationToken(user["id"].(string), body.Email)
+	addr, link := s.smtpAddr, s.appURL+"/verify/"+token
+	s.mu.Unlock()
+
+	msg := "From: PocketBase <noreply@pbtest.local>\r\n" +
+		"To: " + body.Email + "\r\n" +
+		"Subject: Verify your email\r\n" +
+		"\r\n" +
+		"Click the link below to verify your email address.\r\n" +
+		link + "\r\n"
+	if err := smtp.SendMail(addr, nil, "noreply@pbtest.local", []string{body.Email}, []byte(msg)); err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to send verification email.", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// confirmVerification marks the user behind an emailed token as verified.
+func (s *Server) confirmVerification(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Token string `json:"token"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userID, ok := s.verifications[body.Token]
+	_, user := s.collections["users"].find(userID)
+	if !ok || user == nil {
+		writeError(w, http.StatusBadRequest, "Invalid or expired verification token.", nil)
+		return
+	}
+	user["verified"] = true
+	user["updated"] = s.timestamp()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// issueVerificationToken must be called with s.mu held.
+func (s *Server) issueVerificationToken(userID, email string) string {
+	claims, _ := json.Marshal(map[string]any{
+		"id":           userID,
+		"type":         "verification",
+		"collectionId": s.collections["users"].id,
+		"email":        email,
+		"exp":          s.now().Add(TokenTTL).Unix(),
+	})
+	token := "pbtest." + base64.RawURLEncoding.EncodeToString(claims) + "." + newID()
+	s.verifications[token] = userID
+	return token
+}
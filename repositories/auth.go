@@ -10,6 +10,14 @@ import (
 type AuthRepository interface {
 	Authenticate(ctx context.Context, client *pb.Client, email, password string) (string, *pb.User, error)
 	CreateUser(ctx context.Context, client *pb.Client, data map[string]any) error
+	AuthMethods(ctx context.Context, client *pb.Client) (*pb.AuthMethods, error)
+	AuthenticateOAuth2(ctx context.Context, client *pb.Client, provider, code, codeVerifier, redirectURL string) (string, *pb.User, error)
+	RequestPasswordReset(ctx context.Context, client *pb.Client, email string) error
+	ConfirmPasswordReset(ctx context.Context, client *pb.Client, token, password, passwordConfirm string) error
+	RequestVerification(ctx context.Context, client *pb.Client, email string) error
+	ConfirmVerification(ctx context.Context, client *pb.Client, token string) error
+	RequestOTP(ctx context.Context, client *pb.Client, email string) (string, error)
+	AuthenticateOTP(ctx context.Context, client *pb.Client, otpID, code, mfaID string) (string, *pb.User, error)
 }
 
 // PBAuthRepository implements AuthRepository using PocketBase
@@ -20,9 +28,41 @@ func NewAuthRepository() AuthRepository {
 }
 
 func (r *PBAuthRepository) Authenticate(ctx context.Context, client *pb.Client, email, password string) (string, *pb.User, error) {
-	return client.AuthWithPassword(email, password)
+	return client.AuthWithPasswordContext(ctx, email, password)
 }
 
 func (r *PBAuthRepository) CreateUser(ctx context.Context, client *pb.Client, data map[string]any) error {
-	return client.CreateRecord("users", data)
+	return client.CreateRecordContext(ctx, "users", data)
+}
+
+func (r *PBAuthRepository) AuthMethods(ctx context.Context, client *pb.Client) (*pb.AuthMethods, error) {
+	return client.ListAuthMethods(ctx)
+}
+
+func (r *PBAuthRepository) AuthenticateOAuth2(ctx context.Context, client *pb.Client, provider, code, codeVerifier, redirectURL string) (string, *pb.User, error) {
+	return client.AuthWithOAuth2(ctx, provider, code, codeVerifier, redirectURL)
+}
+
+func (r *PBAuthRepository) RequestPasswordReset(ctx context.Context, client *pb.Client, email string) error {
+	return client.RequestPasswordReset(ctx, email)
+}
+
+func (r *PBAuthRepository) ConfirmPasswordReset(ctx context.Context, client *pb.Client, token, password, passwordConfirm string) error {
+	return client.ConfirmPasswordReset(ctx, token, password, passwordConfirm)
+}
+
+func (r *PBAuthRepository) RequestVerification(ctx context.Context, client *pb.Client, email string) error {
+	return client.RequestVerification(ctx, email)
+}
+
+func (r *PBAuthRepository) ConfirmVerification(ctx context.Context, client *pb.Client, token string) error {
+	return client.ConfirmVerification(ctx, token)
+}
+
+func (r *PBAuthRepository) RequestOTP(ctx context.Context, client *pb.Client, email string) (string, error) {
+	return client.RequestOTP(ctx, email)
+}
+
+func (r *PBAuthRepository) AuthenticateOTP(ctx context.Context, client *pb.Client, otpID, code, mfaID string) (string, *pb.User, error) {
+	return client.AuthWithOTP(ctx, otpID, code, mfaID)
 }
@@ -8,7 +8,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/pbtest"
 )
 
 func TestPBAuthRepository(t *testing.T) {
@@ -73,3 +75,50 @@ func TestPBAuthRepository(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+// TestPBAuthRepositoryContract replays a recorded session with a real PocketBase
+// (testdata/cassettes/auth.json). The commander@gosmic.test account must exist
+// when re-recording.
+func TestPBAuthRepositoryContract(t *testing.T) {
+	ctx := context.Background()
+	client := pbtest.CassetteClient(t, "auth")
+	repo := NewAuthRepository()
+
+	t.Run("Authenticate", func(t *testing.T) {
+		token, user, err := repo.Authenticate(ctx, client, "commander@gosmic.test", "password123")
+		require.NoError(t, err)
+		assert.Equal(t, "commander@gosmic.test", user.Email)
+		assert.NotEmpty(t, user.ID)
+		assert.Equal(t, "_pb_users_auth_", user.CollectionID)
+
+		claims, err := pb.ParseToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, claims.ID)
+	})
+
+	t.Run("AuthenticateWrongPassword", func(t *testing.T) {
+		_, _, err := repo.Authenticate(ctx, client, "commander@gosmic.test", "wrong-password")
+		assert.ErrorIs(t, err, pb.ErrBadRequest)
+
+		var apiErr *pb.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "Failed to authenticate.", apiErr.Message)
+	})
+
+	t.Run("CreateUserValidation", func(t *testing.T) {
+		// PocketBase reports one validation stage at a time
+		create := func(password, confirm string) map[string]string {
+			err := repo.CreateUser(ctx, client, map[string]any{
+				"email":           "commander@gosmic.test",
+				"password":        password,
+				"passwordConfirm": confirm,
+			})
+			assert.ErrorIs(t, err, pb.ErrBadRequest)
+			return pb.FieldErrors(err)
+		}
+
+		assert.Equal(t, map[string]string{"passwordConfirm": "Values don't match."}, create("short", "different"))
+		assert.Equal(t, map[string]string{"password": "Must be at least 8 character(s)."}, create("short", "short"))
+		assert.Equal(t, map[string]string{"email": "Value must be unique."}, create("password123", "password123"))
+	})
+}
@@ -23,15 +23,58 @@ func (m *MockAuthRepository) CreateUser(ctx context.Context, client *pb.Client,
 	return args.Error(0)
 }
 
+func (m *MockAuthRepository) AuthMethods(ctx context.Context, client *pb.Client) (*pb.AuthMethods, error) {
+	args := m.Called(ctx, client)
+	methods, _ := args.Get(0).(*pb.AuthMethods)
+	return methods, args.Error(1)
+}
+
+func (m *MockAuthRepository) AuthenticateOAuth2(ctx context.Context, client *pb.Client, provider, code, codeVerifier, redirectURL string) (string, *pb.User, error) {
+	args := m.Called(ctx, client, provider, code, codeVerifier, redirectURL)
+	user, _ := args.Get(1).(*pb.User)
+	return args.String(0), user, args.Error(2)
+}
+
+func (m *MockAuthRepository) RequestPasswordReset(ctx context.Context, client *pb.Client, email string) error {
+	args := m.Called(ctx, client, email)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ConfirmPasswordReset(ctx context.Context, client *pb.Client, token, password, passwordConfirm string) error {
+	args := m.Called(ctx, client, token, password, passwordConfirm)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) RequestVerification(ctx context.Context, client *pb.Client, email string) error {
+	args := m.Called(ctx, client, email)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ConfirmVerification(ctx context.Context, client *pb.Client, token string) error {
+	args := m.Called(ctx, client, token)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) RequestOTP(ctx context.Context, client *pb.Client, email string) (string, error) {
+	args := m.Called(ctx, client, email)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) AuthenticateOTP(ctx context.Context, client *pb.Client, otpID, code, mfaID string) (string, *pb.User, error) {
+	args := m.Called(ctx, client, otpID, code, mfaID)
+	user, _ := args.Get(1).(*pb.User)
+	return args.String(0), user, args.Error(2)
+}
+
 // MockPostRepository is a mock implementation of PostRepository
 type MockPostRepository struct {
 	mock.Mock
 }
 
-func (m *MockPostRepository) List(ctx context.Context, client *pb.Client) ([]pb.Post, error) {
-	args := m.Called(ctx, client)
-	posts, _ := args.Get(0).([]pb.Post)
-	return posts, args.Error(1)
+func (m *MockPostRepository) List(ctx context.Context, client *pb.Client, opts pb.ListOptions) (*pb.ListResult[pb.Post], error) {
+	args := m.Called(ctx, client, opts)
+	result, _ := args.Get(0).(*pb.ListResult[pb.Post])
+	return result, args.Error(1)
 }
 
 func (m *MockPostRepository) Get(ctx context.Context, client *pb.Client, id string) (*pb.Post, error) {
@@ -55,7 +98,51 @@ func (m *MockPostRepository) Delete(ctx context.Context, client *pb.Client, id s
 	return args.Error(0)
 }
 
+func (m *MockPostRepository) DeleteMany(ctx context.Context, client *pb.Client, ids []string) error {
+	args := m.Called(ctx, client, ids)
+	return args.Error(0)
+}
+
 func (m *MockPostRepository) TogglePublic(ctx context.Context, client *pb.Client, id string) error {
 	args := m.Called(ctx, client, id)
 	return args.Error(0)
 }
+
+func (m *MockPostRepository) Subscribe(ctx context.Context, client *pb.Client) (<-chan pb.RealtimeEvent[pb.Post], error) {
+	args := m.Called(ctx, client)
+	events, _ := args.Get(0).(<-chan pb.RealtimeEvent[pb.Post])
+	return events, args.Error(1)
+}
+
+// MockPasskeyRepository is a mock implementation of PasskeyRepository
+type MockPasskeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasskeyRepository) Credentials(ctx context.Context, client *pb.Client, userID string) ([]pb.WebAuthnCredential, error) {
+	args := m.Called(ctx, client, userID)
+	credentials, _ := args.Get(0).([]pb.WebAuthnCredential)
+	return credentials, args.Error(1)
+}
+
+func (m *MockPasskeyRepository) AddCredential(ctx context.Context, client *pb.Client, credential pb.WebAuthnCredential) error {
+	args := m.Called(ctx, client, credential)
+	return args.Error(0)
+}
+
+func (m *MockPasskeyRepository) LookupUser(ctx context.Context, userID string) (*pb.User, []pb.WebAuthnCredential, error) {
+	args := m.Called(ctx, userID)
+	user, _ := args.Get(0).(*pb.User)
+	credentials, _ := args.Get(1).([]pb.WebAuthnCredential)
+	return user, credentials, args.Error(2)
+}
+
+func (m *MockPasskeyRepository) UpdateCredential(ctx context.Context, id string, credential []byte) error {
+	args := m.Called(ctx, id, credential)
+	return args.Error(0)
+}
+
+func (m *MockPasskeyRepository) Impersonate(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
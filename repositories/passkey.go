@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/torresposso/gosmic/pb"
+)
+
+// PasskeyRepository defines the interface for passkey (WebAuthn) credential storage
+type PasskeyRepository interface {
+	// Credentials lists the passkeys of userID visible to client
+	Credentials(ctx context.Context, client *pb.Client, userID string) ([]pb.WebAuthnCredential, error)
+	AddCredential(ctx context.Context, client *pb.Client, credential pb.WebAuthnCredential) error
+	// LookupUser loads a user and their passkeys before anyone is signed in
+	LookupUser(ctx context.Context, userID string) (*pb.User, []pb.WebAuthnCredential, error)
+	// UpdateCredential stores the refreshed credential data (sign count, flags) after a login
+	UpdateCredential(ctx context.Context, id string, credential []byte) error
+	// Impersonate mints a PocketBase session token for userID
+	Impersonate(ctx context.Context, userID string) (string, error)
+}
+
+// PBPasskeyRepository implements PasskeyRepository using PocketBase.
+// Work done on behalf of signed-out users goes through the superuser.
+type PBPasskeyRepository struct {
+	superuser *pb.Superuser
+}
+
+func NewPasskeyRepository(superuser *pb.Superuser) PasskeyRepository {
+	return &PBPasskeyRepository{superuser: superuser}
+}
+
+func (r *PBPasskeyRepository) Credentials(ctx context.Context, client *pb.Client, userID string) ([]pb.WebAuthnCredential, error) {
+	return client.WebAuthnCredentials().FullList(ctx, pb.ListOptions{
+		Filter: pb.WebAuthnCredentialFilter.User.Is(userID),
+	})
+}
+
+func (r *PBPasskeyRepository) AddCredential(ctx context.Context, client *pb.Client, credential pb.WebAuthnCredential) error {
+	_, err := client.WebAuthnCredentials().Create(ctx, map[string]any{
+		"user":         credential.User,
+		"credentialId": credential.CredentialID,
+		"name":         credential.Name,
+		"credential":   credential.Credential,
+	})
+	return err
+}
+
+func (r *PBPasskeyRepository) LookupUser(ctx context.Context, userID string) (*pb.User, []pb.WebAuthnCredential, error) {
+	admin, err := r.superuser.Client(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := admin.Users().Get(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	credentials, err := r.Credentials(ctx, admin, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, credentials, nil
+}
+
+func (r *PBPasskeyRepository) UpdateCredential(ctx context.Context, id string, credential []byte) error {
+	admin, err := r.superuser.Client(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = admin.WebAuthnCredentials().Update(ctx, id, map[string]any{"credential": json.RawMessage(credential)})
+	return err
+}
+
+func (r *PBPasskeyRepository) Impersonate(ctx context.Context, userID string) (string, error) {
+	admin, err := r.superuser.Client(ctx)
+	if err != nil {
+		return "", err
+	}
+	token, _, err := admin.Impersonate(ctx, "users", userID, 0)
+	return token, err
+}
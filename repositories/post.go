@@ -8,12 +8,14 @@ import (
 
 // PostRepository defines the interface for post data access
 type PostRepository interface {
-	List(ctx context.Context, client *pb.Client) ([]pb.Post, error)
+	List(ctx context.Context, client *pb.Client, opts pb.ListOptions) (*pb.ListResult[pb.Post], error)
 	Get(ctx context.Context, client *pb.Client, id string) (*pb.Post, error)
 	Create(ctx context.Context, client *pb.Client, title, content string, isPublic bool) error
 	Update(ctx context.Context, client *pb.Client, id string, data map[string]any) error
 	Delete(ctx context.Context, client *pb.Client, id string) error
+	DeleteMany(ctx context.Context, client *pb.Client, ids []string) error
 	TogglePublic(ctx context.Context, client *pb.Client, id string) error
+	Subscribe(ctx context.Context, client *pb.Client) (<-chan pb.RealtimeEvent[pb.Post], error)
 }
 
 // PBPostRepository implements PostRepository using PocketBase
@@ -23,32 +25,48 @@ func NewPostRepository() PostRepository {
 	return &PBPostRepository{}
 }
 
-func (r *PBPostRepository) List(ctx context.Context, client *pb.Client) ([]pb.Post, error) {
-	return client.ListPosts()
+func (r *PBPostRepository) List(ctx context.Context, client *pb.Client, opts pb.ListOptions) (*pb.ListResult[pb.Post], error) {
+	return client.Posts().List(ctx, opts)
 }
 
 func (r *PBPostRepository) Get(ctx context.Context, client *pb.Client, id string) (*pb.Post, error) {
-	return client.GetPost(id)
+	return client.GetPostContext(ctx, id)
 }
 
 func (r *PBPostRepository) Create(ctx context.Context, client *pb.Client, title, content string, isPublic bool) error {
-	return client.CreatePost(title, content, isPublic)
+	return client.CreatePostContext(ctx, title, content, isPublic)
 }
 
 func (r *PBPostRepository) Update(ctx context.Context, client *pb.Client, id string, data map[string]any) error {
-	return client.UpdatePost(id, data)
+	return client.UpdatePostContext(ctx, id, data)
 }
 
 func (r *PBPostRepository) Delete(ctx context.Context, client *pb.Client, id string) error {
-	return client.DeletePost(id)
+	return client.DeletePostContext(ctx, id)
+}
+
+// DeleteMany removes the posts in a single transaction: if any of them cannot
+// be deleted, none is.
+func (r *PBPostRepository) DeleteMany(ctx context.Context, client *pb.Client, ids []string) error {
+	batch := client.Batch()
+	for _, id := range ids {
+		batch.Delete("posts", id)
+	}
+	_, err := batch.Send(ctx)
+	return err
 }
 
 func (r *PBPostRepository) TogglePublic(ctx context.Context, client *pb.Client, id string) error {
-	post, err := client.GetPost(id)
+	post, err := client.GetPostContext(ctx, id)
 	if err != nil {
 		return err
 	}
-	return client.UpdatePost(id, map[string]any{
+	return client.UpdatePostContext(ctx, id, map[string]any{
 		"public": !post.Public,
 	})
 }
+
+// Subscribe streams realtime changes to every post the client is allowed to view
+func (r *PBPostRepository) Subscribe(ctx context.Context, client *pb.Client) (<-chan pb.RealtimeEvent[pb.Post], error) {
+	return pb.Subscribe[pb.Post](ctx, client, "posts/*")
+}
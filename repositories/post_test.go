@@ -3,12 +3,15 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/pbtest"
 )
 
 func TestPBPostRepository(t *testing.T) {
@@ -29,10 +32,42 @@ func TestPBPostRepository(t *testing.T) {
 		client := pb.NewClient(server.URL)
 		repo := NewPostRepository()
 
-		posts, err := repo.List(ctx, client)
+		result, err := repo.List(ctx, client, pb.ListOptions{})
 		assert.NoError(t, err)
-		assert.Len(t, posts, 1)
-		assert.Equal(t, "p1", posts[0].ID)
+		assert.Len(t, result.Items, 1)
+		assert.Equal(t, "p1", result.Items[0].ID)
+	})
+
+	t.Run("List_WithOptions", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/collections/posts/records", r.URL.Path)
+			assert.Equal(t, "3", r.URL.Query().Get("page"))
+			assert.Equal(t, "5", r.URL.Query().Get("perPage"))
+			assert.Equal(t, "-created", r.URL.Query().Get("sort"))
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]any{
+				"page":       3,
+				"perPage":    5,
+				"totalItems": 12,
+				"totalPages": 3,
+				"items": []map[string]any{
+					{"id": "p11", "title": "Post 11"},
+					{"id": "p12", "title": "Post 12"},
+				},
+			})
+		}))
+		defer server.Close()
+
+		client := pb.NewClient(server.URL)
+		repo := NewPostRepository()
+
+		result, err := repo.List(ctx, client, pb.ListOptions{Page: 3, PerPage: 5, Sort: "-created"})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, result.Page)
+		assert.Equal(t, 12, result.TotalItems)
+		assert.Equal(t, 3, result.TotalPages)
+		assert.False(t, result.HasMore())
+		assert.Len(t, result.Items, 2)
 	})
 
 	t.Run("Get_Success", func(t *testing.T) {
@@ -98,6 +133,47 @@ func TestPBPostRepository(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("DeleteMany_Success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/batch", r.URL.Path)
+			var body struct {
+				Requests []map[string]any `json:"requests"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, []map[string]any{
+				{"method": "DELETE", "url": "/api/collections/posts/records/p1"},
+				{"method": "DELETE", "url": "/api/collections/posts/records/p2"},
+			}, body.Requests)
+			json.NewEncoder(w).Encode([]map[string]any{{"status": 204}, {"status": 204}})
+		}))
+		defer server.Close()
+
+		client := pb.NewClient(server.URL)
+		client.AuthToken = "dummy"
+		repo := NewPostRepository()
+
+		err := repo.DeleteMany(ctx, client, []string{"p1", "p2"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("DeleteMany_RolledBack", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"message":"Batch transaction failed.","data":{"requests":{"1":{"code":"batch_request_failed","message":"Batch request failed.","response":{"status":404,"message":"The requested resource wasn't found.","data":{}}}}}}`))
+		}))
+		defer server.Close()
+
+		client := pb.NewClient(server.URL)
+		client.AuthToken = "dummy"
+		repo := NewPostRepository()
+
+		err := repo.DeleteMany(ctx, client, []string{"p1", "missing"})
+		assert.ErrorIs(t, err, pb.ErrNotFound)
+		var batchErr *pb.BatchError
+		assert.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, 1, batchErr.Index)
+	})
+
 	t.Run("TogglePublic_Success", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if r.Method == "GET" {
@@ -121,4 +197,92 @@ func TestPBPostRepository(t *testing.T) {
 		err := repo.TogglePublic(ctx, client, "p1")
 		assert.NoError(t, err)
 	})
+
+	t.Run("List_ContextCancelled", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		client := pb.NewClient(server.URL)
+		repo := NewPostRepository()
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		result, err := repo.List(cancelled, client, pb.ListOptions{})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, result)
+	})
+
+	t.Run("Subscribe_AllPosts", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/realtime", r.URL.Path)
+			if r.Method == http.MethodPost {
+				var body map[string]any
+				json.NewDecoder(r.Body).Decode(&body)
+				assert.Equal(t, []any{"posts/*"}, body["subscriptions"])
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.Write([]byte("event:PB_CONNECT\ndata:{\"clientId\":\"c1\"}\n\n"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		client := pb.NewClient(server.URL)
+		repo := NewPostRepository()
+
+		subCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		events, err := repo.Subscribe(subCtx, client)
+		assert.NoError(t, err)
+		assert.NotNil(t, events)
+	})
+}
+
+// TestPBPostRepositoryContract replays a recorded session with a real PocketBase
+// (testdata/cassettes/posts.json) to catch drift in the records API. The
+// commander@gosmic.test account must exist when re-recording.
+func TestPBPostRepositoryContract(t *testing.T) {
+	ctx := context.Background()
+	client := pbtest.CassetteClient(t, "posts")
+
+	token, user, err := NewAuthRepository().Authenticate(ctx, client, "commander@gosmic.test", "password123")
+	require.NoError(t, err)
+	client = client.WithToken(token)
+	client.AuthRecord = user
+	repo := NewPostRepository()
+
+	require.NoError(t, repo.Create(ctx, client, "Contract probe", "Recorded for contract tests", false))
+
+	result, err := repo.List(ctx, client, pb.ListOptions{
+		Filter:  fmt.Sprintf(`title = "Contract probe" && author = %q`, user.ID),
+		Sort:    "-created",
+		PerPage: 1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Page)
+	assert.Equal(t, 1, result.PerPage)
+	require.Len(t, result.Items, 1)
+	probe := result.Items[0]
+	assert.NotEmpty(t, probe.ID)
+	assert.Equal(t, "Recorded for contract tests", probe.Content)
+	assert.Equal(t, user.ID, probe.Author)
+	assert.False(t, probe.Public)
+	assert.NotEmpty(t, probe.Created)
+
+	require.NoError(t, repo.TogglePublic(ctx, client, probe.ID))
+	require.NoError(t, repo.Update(ctx, client, probe.ID, map[string]any{"title": "Contract probe (edited)"}))
+
+	post, err := repo.Get(ctx, client, probe.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Contract probe (edited)", post.Title)
+	assert.True(t, post.Public)
+
+	require.NoError(t, repo.Delete(ctx, client, probe.ID))
+	_, err = repo.Get(ctx, client, probe.ID)
+	assert.ErrorIs(t, err, pb.ErrNotFound)
 }
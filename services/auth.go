@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 
 	"github.com/torresposso/gosmic/pb"
 	"github.com/torresposso/gosmic/repositories"
@@ -12,6 +13,14 @@ import (
 type AuthService interface {
 	Login(ctx context.Context, client *pb.Client, email, password string) (string, error)
 	Register(ctx context.Context, client *pb.Client, email, password, name string) error
+	OAuth2Providers(ctx context.Context, client *pb.Client) ([]pb.AuthProvider, error)
+	LoginWithOAuth2(ctx context.Context, client *pb.Client, provider, code, codeVerifier, redirectURL string) (string, error)
+	RequestPasswordReset(ctx context.Context, client *pb.Client, email string) error
+	ResetPassword(ctx context.Context, client *pb.Client, token, password, passwordConfirm string) error
+	ResendVerification(ctx context.Context, client *pb.Client, email string) error
+	VerifyEmail(ctx context.Context, client *pb.Client, token string) error
+	RequestOTP(ctx context.Context, client *pb.Client, email string) (string, error)
+	LoginWithOTP(ctx context.Context, client *pb.Client, otpID, code, mfaID string) (string, error)
 }
 
 type authService struct {
@@ -22,6 +31,9 @@ func NewAuthService(repo repositories.AuthRepository) AuthService {
 	return &authService{repo: repo}
 }
 
+// Login signs in with email and password. When the account requires a second
+// factor the error matches pb.ErrMFARequired and carries the *pb.MFAError to
+// continue with LoginWithOTP.
 func (s *authService) Login(ctx context.Context, client *pb.Client, email, password string) (string, error) {
 	if email == "" || password == "" {
 		return "", errors.New("email and password are required")
@@ -51,5 +63,112 @@ func (s *authService) Register(ctx context.Context, client *pb.Client, email, pa
 		"name":            name,
 	}
 
-	return s.repo.CreateUser(ctx, client, data)
+	if err := s.repo.CreateUser(ctx, client, data); err != nil {
+		return err
+	}
+
+	// The account exists either way; a failed email can be resent from the dashboard
+	if err := s.repo.RequestVerification(ctx, client, email); err != nil {
+		log.Printf("failed to send verification email: %v", err)
+	}
+	return nil
+}
+
+// OAuth2Providers returns the OAuth2 providers users can sign in with,
+// or none when OAuth2 is disabled for the users collection.
+func (s *authService) OAuth2Providers(ctx context.Context, client *pb.Client) ([]pb.AuthProvider, error) {
+	methods, err := s.repo.AuthMethods(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if !methods.OAuth2.Enabled {
+		return nil, nil
+	}
+	return methods.OAuth2.Providers, nil
+}
+
+func (s *authService) LoginWithOAuth2(ctx context.Context, client *pb.Client, provider, code, codeVerifier, redirectURL string) (string, error) {
+	if provider == "" || code == "" || codeVerifier == "" {
+		return "", errors.New("incomplete oauth2 sign-in")
+	}
+
+	token, _, err := s.repo.AuthenticateOAuth2(ctx, client, provider, code, codeVerifier, redirectURL)
+	if err != nil {
+		return "", fmt.Errorf("authentication failed: %w", err)
+	}
+
+	return token, nil
+}
+
+// RequestPasswordReset sends a reset link to email. It succeeds whether or not
+// the address belongs to an account, so callers cannot be used to probe for users.
+func (s *authService) RequestPasswordReset(ctx context.Context, client *pb.Client, email string) error {
+	if email == "" {
+		return errors.New("email is required")
+	}
+
+	err := s.repo.RequestPasswordReset(ctx, client, email)
+	if errors.Is(err, pb.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *authService) ResetPassword(ctx context.Context, client *pb.Client, token, password, passwordConfirm string) error {
+	if token == "" {
+		return errors.New("reset link is invalid or has expired")
+	}
+	if password == "" || passwordConfirm == "" {
+		return errors.New("all fields are required")
+	}
+	if len(password) < 8 {
+		return errors.New("password must be at least 8 characters")
+	}
+	if password != passwordConfirm {
+		return errors.New("passwords do not match")
+	}
+
+	return s.repo.ConfirmPasswordReset(ctx, client, token, password, passwordConfirm)
+}
+
+// ResendVerification sends another verification link to email.
+func (s *authService) ResendVerification(ctx context.Context, client *pb.Client, email string) error {
+	if email == "" {
+		return errors.New("email is required")
+	}
+	return s.repo.RequestVerification(ctx, client, email)
+}
+
+// VerifyEmail confirms the account behind the token from the verification email.
+func (s *authService) VerifyEmail(ctx context.Context, client *pb.Client, token string) error {
+	if token == "" {
+		return errors.New("verification link is invalid or has expired")
+	}
+	return s.repo.ConfirmVerification(ctx, client, token)
+}
+
+// RequestOTP emails a one-time code to email and returns its otpId.
+func (s *authService) RequestOTP(ctx context.Context, client *pb.Client, email string) (string, error) {
+	if email == "" {
+		return "", errors.New("email is required")
+	}
+	return s.repo.RequestOTP(ctx, client, email)
+}
+
+// LoginWithOTP finishes a sign-in with the emailed code. mfaID is the id from
+// the pb.MFAError of a password login that needs a second factor.
+func (s *authService) LoginWithOTP(ctx context.Context, client *pb.Client, otpID, code, mfaID string) (string, error) {
+	if otpID == "" {
+		return "", errors.New("sign-in session expired, please start again")
+	}
+	if code == "" {
+		return "", errors.New("code is required")
+	}
+
+	token, _, err := s.repo.AuthenticateOTP(ctx, client, otpID, code, mfaID)
+	if err != nil {
+		return "", fmt.Errorf("authentication failed: %w", err)
+	}
+
+	return token, nil
 }
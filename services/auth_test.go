@@ -67,6 +67,7 @@ func TestAuthService_Register(t *testing.T) {
 		mockRepo.On("CreateUser", ctx, client, mock.MatchedBy(func(data map[string]any) bool {
 			return data["email"] == email && data["password"] == password && data["name"] == name
 		})).Return(nil).Once()
+		mockRepo.On("RequestVerification", ctx, client, email).Return(nil).Once()
 
 		err := service.Register(ctx, client, email, password, name)
 
@@ -74,6 +75,16 @@ func TestAuthService_Register(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("VerificationEmailFailureKeepsAccount", func(t *testing.T) {
+		mockRepo.On("CreateUser", ctx, client, mock.Anything).Return(nil).Once()
+		mockRepo.On("RequestVerification", ctx, client, "mailer@example.com").Return(errors.New("smtp down")).Once()
+
+		err := service.Register(ctx, client, "mailer@example.com", "password123", "Name")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("MissingFields", func(t *testing.T) {
 		err := service.Register(ctx, client, "", "password", "name")
 		assert.Error(t, err)
@@ -96,3 +107,146 @@ func TestAuthService_Register(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestAuthService_OAuth2(t *testing.T) {
+	mockRepo := new(repositories.MockAuthRepository)
+	service := NewAuthService(mockRepo)
+	ctx := context.Background()
+	client := &pb.Client{}
+
+	t.Run("Providers", func(t *testing.T) {
+		methods := &pb.AuthMethods{}
+		methods.OAuth2.Enabled = true
+		methods.OAuth2.Providers = []pb.AuthProvider{{Name: "github"}}
+		mockRepo.On("AuthMethods", ctx, client).Return(methods, nil).Once()
+
+		providers, err := service.OAuth2Providers(ctx, client)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []pb.AuthProvider{{Name: "github"}}, providers)
+	})
+
+	t.Run("ProvidersDisabled", func(t *testing.T) {
+		methods := &pb.AuthMethods{}
+		methods.OAuth2.Providers = []pb.AuthProvider{{Name: "github"}}
+		mockRepo.On("AuthMethods", ctx, client).Return(methods, nil).Once()
+
+		providers, err := service.OAuth2Providers(ctx, client)
+
+		assert.NoError(t, err)
+		assert.Empty(t, providers)
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		mockRepo.On("AuthenticateOAuth2", ctx, client, "github", "code", "verifier", "http://app/callback").
+			Return("oauth2-token", &pb.User{}, nil).Once()
+
+		token, err := service.LoginWithOAuth2(ctx, client, "github", "code", "verifier", "http://app/callback")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "oauth2-token", token)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("LoginMissingCode", func(t *testing.T) {
+		token, err := service.LoginWithOAuth2(ctx, client, "github", "", "verifier", "http://app/callback")
+
+		assert.Error(t, err)
+		assert.Empty(t, token)
+	})
+}
+
+func TestAuthService_PasswordReset(t *testing.T) {
+	mockRepo := new(repositories.MockAuthRepository)
+	service := NewAuthService(mockRepo)
+	ctx := context.Background()
+	client := &pb.Client{}
+
+	t.Run("RequestUnknownEmail", func(t *testing.T) {
+		mockRepo.On("RequestPasswordReset", ctx, client, "ghost@example.com").
+			Return(&pb.APIError{Status: 404, Message: "Not found."}).Once()
+
+		err := service.RequestPasswordReset(ctx, client, "ghost@example.com")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("RequestMissingEmail", func(t *testing.T) {
+		err := service.RequestPasswordReset(ctx, client, "")
+		assert.Equal(t, "email is required", err.Error())
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		mockRepo.On("ConfirmPasswordReset", ctx, client, "token", "password123", "password123").Return(nil).Once()
+
+		err := service.ResetPassword(ctx, client, "token", "password123", "password123")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ResetValidation", func(t *testing.T) {
+		assert.EqualError(t, service.ResetPassword(ctx, client, "", "password123", "password123"), "reset link is invalid or has expired")
+		assert.EqualError(t, service.ResetPassword(ctx, client, "token", "short", "short"), "password must be at least 8 characters")
+		assert.EqualError(t, service.ResetPassword(ctx, client, "token", "password123", "password456"), "passwords do not match")
+	})
+}
+
+func TestAuthService_Verification(t *testing.T) {
+	mockRepo := new(repositories.MockAuthRepository)
+	service := NewAuthService(mockRepo)
+	ctx := context.Background()
+	client := &pb.Client{}
+
+	t.Run("Resend", func(t *testing.T) {
+		mockRepo.On("RequestVerification", ctx, client, "test@example.com").Return(nil).Once()
+
+		assert.NoError(t, service.ResendVerification(ctx, client, "test@example.com"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		mockRepo.On("ConfirmVerification", ctx, client, "verify-token").Return(nil).Once()
+
+		assert.NoError(t, service.VerifyEmail(ctx, client, "verify-token"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("VerifyMissingToken", func(t *testing.T) {
+		assert.Error(t, service.VerifyEmail(ctx, client, ""))
+	})
+}
+
+func TestAuthService_OTP(t *testing.T) {
+	mockRepo := new(repositories.MockAuthRepository)
+	service := NewAuthService(mockRepo)
+	ctx := context.Background()
+	client := &pb.Client{}
+
+	t.Run("LoginRequiresMFA", func(t *testing.T) {
+		mockRepo.On("Authenticate", ctx, client, "test@example.com", "password123").
+			Return("", nil, &pb.MFAError{MFAID: "mfa-1"}).Once()
+
+		_, err := service.Login(ctx, client, "test@example.com", "password123")
+
+		var mfaErr *pb.MFAError
+		assert.ErrorAs(t, err, &mfaErr)
+		assert.Equal(t, "mfa-1", mfaErr.MFAID)
+	})
+
+	t.Run("LoginWithOTP", func(t *testing.T) {
+		mockRepo.On("AuthenticateOTP", ctx, client, "otp-1", "123456", "mfa-1").Return("mfa-token", &pb.User{}, nil).Once()
+
+		token, err := service.LoginWithOTP(ctx, client, "otp-1", "123456", "mfa-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "mfa-token", token)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("LoginWithOTPMissingCode", func(t *testing.T) {
+		_, err := service.LoginWithOTP(ctx, client, "otp-1", "", "mfa-1")
+		assert.EqualError(t, err, "code is required")
+	})
+}
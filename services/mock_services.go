@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 
+	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/stretchr/testify/mock"
 	"github.com/torresposso/gosmic/pb"
 )
@@ -12,10 +13,10 @@ type MockPostService struct {
 	mock.Mock
 }
 
-func (m *MockPostService) List(ctx context.Context, client *pb.Client, query string) ([]pb.Post, error) {
+func (m *MockPostService) List(ctx context.Context, client *pb.Client, query PostQuery) (*pb.ListResult[pb.Post], error) {
 	args := m.Called(ctx, client, query)
-	posts, _ := args.Get(0).([]pb.Post)
-	return posts, args.Error(1)
+	result, _ := args.Get(0).(*pb.ListResult[pb.Post])
+	return result, args.Error(1)
 }
 
 func (m *MockPostService) Get(ctx context.Context, client *pb.Client, id string) (*pb.Post, error) {
@@ -43,3 +44,42 @@ func (m *MockPostService) TogglePublic(ctx context.Context, client *pb.Client, i
 	args := m.Called(ctx, client, id)
 	return args.Error(0)
 }
+
+func (m *MockPostService) Subscribe(ctx context.Context, client *pb.Client) (<-chan pb.RealtimeEvent[pb.Post], error) {
+	args := m.Called(ctx, client)
+	events, _ := args.Get(0).(<-chan pb.RealtimeEvent[pb.Post])
+	return events, args.Error(1)
+}
+
+// MockPasskeyService is a mock implementation of PasskeyService
+type MockPasskeyService struct {
+	mock.Mock
+}
+
+func (m *MockPasskeyService) Passkeys(ctx context.Context, client *pb.Client) ([]pb.WebAuthnCredential, error) {
+	args := m.Called(ctx, client)
+	credentials, _ := args.Get(0).([]pb.WebAuthnCredential)
+	return credentials, args.Error(1)
+}
+
+func (m *MockPasskeyService) BeginRegistration(ctx context.Context, client *pb.Client) (*protocol.CredentialCreation, string, error) {
+	args := m.Called(ctx, client)
+	options, _ := args.Get(0).(*protocol.CredentialCreation)
+	return options, args.String(1), args.Error(2)
+}
+
+func (m *MockPasskeyService) FinishRegistration(ctx context.Context, client *pb.Client, state, response, name string) error {
+	args := m.Called(ctx, client, state, response, name)
+	return args.Error(0)
+}
+
+func (m *MockPasskeyService) BeginLogin(ctx context.Context) (*protocol.CredentialAssertion, string, error) {
+	args := m.Called(ctx)
+	options, _ := args.Get(0).(*protocol.CredentialAssertion)
+	return options, args.String(1), args.Error(2)
+}
+
+func (m *MockPasskeyService) FinishLogin(ctx context.Context, state, response string) (string, error) {
+	args := m.Called(ctx, state, response)
+	return args.String(0), args.Error(1)
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/repositories"
+)
+
+// ErrPasskeyRejected is returned when a passkey ceremony fails verification.
+var ErrPasskeyRejected = errors.New("passkey was not accepted")
+
+// PasskeyService runs the WebAuthn ceremonies for passkey registration and sign-in.
+// Each Begin call returns the options for navigator.credentials and an opaque state
+// that must be kept server-side and passed back to the matching Finish call.
+type PasskeyService interface {
+	// Passkeys lists the passkeys registered by the signed-in user
+	Passkeys(ctx context.Context, client *pb.Client) ([]pb.WebAuthnCredential, error)
+	BeginRegistration(ctx context.Context, client *pb.Client) (*protocol.CredentialCreation, string, error)
+	FinishRegistration(ctx context.Context, client *pb.Client, state, response, name string) error
+	BeginLogin(ctx context.Context) (*protocol.CredentialAssertion, string, error)
+	// FinishLogin verifies the assertion and returns a PocketBase token for its owner
+	FinishLogin(ctx context.Context, state, response string) (string, error)
+}
+
+type passkeyService struct {
+	webauthn *webauthn.WebAuthn
+	repo     repositories.PasskeyRepository
+}
+
+func NewPasskeyService(wa *webauthn.WebAuthn, repo repositories.PasskeyRepository) PasskeyService {
+	return &passkeyService{webauthn: wa, repo: repo}
+}
+
+// recordID matches PocketBase record ids; user handles are checked against it
+// before they are used in lookups.
+var recordID = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// passkeyUser adapts a PocketBase user and their stored passkeys to webauthn.User.
+type passkeyUser struct {
+	user        *pb.User
+	credentials []webauthn.Credential
+}
+
+func (u *passkeyUser) WebAuthnID() []byte {
+	return []byte(u.user.ID)
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	if u.user.Name != "" {
+		return u.user.Name
+	}
+	return u.user.Email
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func newPasskeyUser(user *pb.User, stored []pb.WebAuthnCredential) (*passkeyUser, error) {
+	credentials := make([]webauthn.Credential, 0, len(stored))
+	for _, record := range stored {
+		var credential webauthn.Credential
+		if err := json.Unmarshal(record.Credential, &credential); err != nil {
+			return nil, fmt.Errorf("failed to decode passkey %s: %w", record.ID, err)
+		}
+		credentials = append(credentials, credential)
+	}
+	return &passkeyUser{user: user, credentials: credentials}, nil
+}
+
+func (s *passkeyService) Passkeys(ctx context.Context, client *pb.Client) ([]pb.WebAuthnCredential, error) {
+	if client.AuthRecord == nil || client.AuthRecord.ID == "" {
+		return nil, pb.ErrUnauthorized
+	}
+	return s.repo.Credentials(ctx, client, client.AuthRecord.ID)
+}
+
+func (s *passkeyService) BeginRegistration(ctx context.Context, client *pb.Client) (*protocol.CredentialCreation, string, error) {
+	if client.AuthRecord == nil || client.AuthRecord.ID == "" {
+		return nil, "", pb.ErrUnauthorized
+	}
+
+	stored, err := s.repo.Credentials(ctx, client, client.AuthRecord.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	user, err := newPasskeyUser(client.AuthRecord, stored)
+	if err != nil {
+		return nil, "", err
+	}
+
+	options, session, err := s.webauthn.BeginRegistration(user,
+		webauthn.WithExclusions(webauthn.Credentials(user.credentials).CredentialDescriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	state, err := json.Marshal(session)
+	if err != nil {
+		return nil, "", err
+	}
+	return options, string(state), nil
+}
+
+func (s *passkeyService) FinishRegistration(ctx context.Context, client *pb.Client, state, response, name string) error {
+	if client.AuthRecord == nil || client.AuthRecord.ID == "" {
+		return pb.ErrUnauthorized
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal([]byte(state), &session); err != nil {
+		return fmt.Errorf("%w: registration expired", ErrPasskeyRejected)
+	}
+	// The ceremony must be finished by the user who started it
+	if string(session.UserID) != client.AuthRecord.ID {
+		return fmt.Errorf("%w: registration belongs to another user", ErrPasskeyRejected)
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes([]byte(response))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPasskeyRejected, err)
+	}
+
+	credential, err := s.webauthn.CreateCredential(&passkeyUser{user: client.AuthRecord}, session, parsed)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPasskeyRejected, err)
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = "Passkey"
+	}
+
+	return s.repo.AddCredential(ctx, client, pb.WebAuthnCredential{
+		User:         client.AuthRecord.ID,
+		CredentialID: base64.RawURLEncoding.EncodeToString(credential.ID),
+		Name:         name,
+		Credential:   data,
+	})
+}
+
+func (s *passkeyService) BeginLogin(ctx context.Context) (*protocol.CredentialAssertion, string, error) {
+	options, session, err := s.webauthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationPreferred),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	state, err := json.Marshal(session)
+	if err != nil {
+		return nil, "", err
+	}
+	return options, string(state), nil
+}
+
+func (s *passkeyService) FinishLogin(ctx context.Context, state, response string) (string, error) {
+	var session webauthn.SessionData
+	if err := json.Unmarshal([]byte(state), &session); err != nil {
+		return "", fmt.Errorf("%w: sign-in expired", ErrPasskeyRejected)
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes([]byte(response))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrPasskeyRejected, err)
+	}
+
+	// The authenticator tells us whose passkey it is through the user handle
+	var owner *pb.User
+	var stored []pb.WebAuthnCredential
+	lookup := func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID := string(userHandle)
+		if !recordID.MatchString(userID) {
+			return nil, errors.New("unknown user handle")
+		}
+
+		var err error
+		owner, stored, err = s.repo.LookupUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		return newPasskeyUser(owner, stored)
+	}
+
+	credential, err := s.webauthn.ValidateDiscoverableLogin(lookup, session, parsed)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrPasskeyRejected, err)
+	}
+	if credential.Authenticator.CloneWarning {
+		return "", fmt.Errorf("%w: signature counter went backwards, the passkey may be cloned", ErrPasskeyRejected)
+	}
+
+	// Persist the new sign count so replayed or cloned authenticators are caught next time
+	credentialID := base64.RawURLEncoding.EncodeToString(credential.ID)
+	for _, record := range stored {
+		if record.CredentialID != credentialID {
+			continue
+		}
+		data, err := json.Marshal(credential)
+		if err != nil {
+			return "", err
+		}
+		if err := s.repo.UpdateCredential(ctx, record.ID, data); err != nil {
+			return "", err
+		}
+	}
+
+	return s.repo.Impersonate(ctx, owner.ID)
+}
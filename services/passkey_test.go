@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/repositories"
+)
+
+const (
+	testRPID   = "gosmic.test"
+	testOrigin = "https://gosmic.test"
+)
+
+var b64 = base64.RawURLEncoding
+
+// softAuthenticator is a minimal in-memory passkey: one P-256 key, "none" attestation.
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	require.NoError(t, err)
+	return &softAuthenticator{key: key, credentialID: id}
+}
+
+func (a *softAuthenticator) authData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append([]byte{}, rpIDHash[:]...)
+
+	flags := byte(0x01 | 0x04) // user present, user verified
+	if attested {
+		flags |= 0x40
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+
+	if attested {
+		x := make([]byte, 32)
+		y := make([]byte, 32)
+		a.key.PublicKey.X.FillBytes(x)
+		a.key.PublicKey.Y.FillBytes(y)
+		coseKey, _ := webauthncbor.Marshal(map[int]any{1: 2, 3: -7, -1: 1, -2: x, -3: y})
+
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, coseKey...)
+	}
+	return data
+}
+
+func clientData(ceremony, challenge string) []byte {
+	data, _ := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": challenge,
+		"origin":    testOrigin,
+	})
+	return data
+}
+
+// create answers navigator.credentials.create
+func (a *softAuthenticator) create(t *testing.T, challenge string, userHandle []byte) string {
+	a.userHandle = userHandle
+	a.signCount = 1
+
+	attestation, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": a.authData(true),
+	})
+	require.NoError(t, err)
+
+	response, _ := json.Marshal(map[string]any{
+		"id":    b64.EncodeToString(a.credentialID),
+		"rawId": b64.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    b64.EncodeToString(clientData("webauthn.create", challenge)),
+			"attestationObject": b64.EncodeToString(attestation),
+		},
+	})
+	return string(response)
+}
+
+// get answers navigator.credentials.get
+func (a *softAuthenticator) get(t *testing.T, challenge string) string {
+	a.signCount++
+	authData := a.authData(false)
+	data := clientData("webauthn.get", challenge)
+
+	clientHash := sha256.Sum256(data)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	response, _ := json.Marshal(map[string]any{
+		"id":    b64.EncodeToString(a.credentialID),
+		"rawId": b64.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    b64.EncodeToString(data),
+			"authenticatorData": b64.EncodeToString(authData),
+			"signature":         b64.EncodeToString(signature),
+			"userHandle":        b64.EncodeToString(a.userHandle),
+		},
+	})
+	return string(response)
+}
+
+func newTestPasskeyService(t *testing.T, repo repositories.PasskeyRepository) PasskeyService {
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "Gosmic Code",
+		RPOrigins:     []string{testOrigin},
+	})
+	require.NoError(t, err)
+	return NewPasskeyService(wa, repo)
+}
+
+func TestPasskeyService_RegisterAndLogin(t *testing.T) {
+	mockRepo := new(repositories.MockPasskeyRepository)
+	service := newTestPasskeyService(t, mockRepo)
+	ctx := context.Background()
+	user := &pb.User{ID: "abc123def456ghi", Email: "pilot@fleet.com", Name: "Pilot"}
+	client := &pb.Client{AuthRecord: user}
+	authenticator := newSoftAuthenticator(t)
+
+	// Registration
+	mockRepo.On("Credentials", ctx, client, user.ID).Return([]pb.WebAuthnCredential{}, nil).Once()
+	creation, state, err := service.BeginRegistration(ctx, client)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(user.ID), []byte(creation.Response.User.ID.(protocol.URLEncodedBase64)))
+
+	var stored pb.WebAuthnCredential
+	mockRepo.On("AddCredential", ctx, client, mock.AnythingOfType("pb.WebAuthnCredential")).
+		Run(func(args mock.Arguments) {
+			stored = args.Get(2).(pb.WebAuthnCredential)
+			stored.ID = "cred-record-1"
+		}).Return(nil).Once()
+
+	response := authenticator.create(t, creation.Response.Challenge.String(), []byte(user.ID))
+	err = service.FinishRegistration(ctx, client, state, response, "Laptop")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, stored.User)
+	assert.Equal(t, "Laptop", stored.Name)
+	assert.Equal(t, b64.EncodeToString(authenticator.credentialID), stored.CredentialID)
+
+	// Login
+	assertion, state, err := service.BeginLogin(ctx)
+	require.NoError(t, err)
+
+	mockRepo.On("LookupUser", ctx, user.ID).Return(user, []pb.WebAuthnCredential{stored}, nil).Once()
+	mockRepo.On("UpdateCredential", ctx, "cred-record-1", mock.Anything).Return(nil).Once()
+	mockRepo.On("Impersonate", ctx, user.ID).Return("impersonated-token", nil).Once()
+
+	token, err := service.FinishLogin(ctx, state, authenticator.get(t, assertion.Response.Challenge.String()))
+	require.NoError(t, err)
+	assert.Equal(t, "impersonated-token", token)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPasskeyService_LoginRejected(t *testing.T) {
+	ctx := context.Background()
+	user := &pb.User{ID: "abc123def456ghi", Email: "pilot@fleet.com"}
+
+	// register enrolls a fresh authenticator and returns its stored record
+	register := func(t *testing.T, service PasskeyService, mockRepo *repositories.MockPasskeyRepository) (*softAuthenticator, pb.WebAuthnCredential) {
+		client := &pb.Client{AuthRecord: user}
+		authenticator := newSoftAuthenticator(t)
+		var stored pb.WebAuthnCredential
+
+		mockRepo.On("Credentials", ctx, client, user.ID).Return([]pb.WebAuthnCredential{}, nil).Once()
+		mockRepo.On("AddCredential", ctx, client, mock.Anything).
+			Run(func(args mock.Arguments) {
+				stored = args.Get(2).(pb.WebAuthnCredential)
+				stored.ID = "cred-record-1"
+			}).Return(nil).Once()
+
+		creation, state, err := service.BeginRegistration(ctx, client)
+		require.NoError(t, err)
+		response := authenticator.create(t, creation.Response.Challenge.String(), []byte(user.ID))
+		require.NoError(t, service.FinishRegistration(ctx, client, state, response, ""))
+		return authenticator, stored
+	}
+
+	t.Run("ClonedAuthenticator", func(t *testing.T) {
+		mockRepo := new(repositories.MockPasskeyRepository)
+		service := newTestPasskeyService(t, mockRepo)
+		authenticator, stored := register(t, service, mockRepo)
+		assert.Equal(t, "Passkey", stored.Name)
+
+		// A copy of the key replays the registration sign count
+		authenticator.signCount = 0
+		assertion, state, err := service.BeginLogin(ctx)
+		require.NoError(t, err)
+		mockRepo.On("LookupUser", ctx, user.ID).Return(user, []pb.WebAuthnCredential{stored}, nil).Once()
+
+		token, err := service.FinishLogin(ctx, state, authenticator.get(t, assertion.Response.Challenge.String()))
+		assert.ErrorIs(t, err, ErrPasskeyRejected)
+		assert.Empty(t, token)
+		mockRepo.AssertNotCalled(t, "Impersonate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ForgedUserHandle", func(t *testing.T) {
+		mockRepo := new(repositories.MockPasskeyRepository)
+		service := newTestPasskeyService(t, mockRepo)
+		authenticator, _ := register(t, service, mockRepo)
+
+		// The handle is never used to look anything up
+		authenticator.userHandle = []byte(`x" || id != "`)
+		assertion, state, err := service.BeginLogin(ctx)
+		require.NoError(t, err)
+
+		_, err = service.FinishLogin(ctx, state, authenticator.get(t, assertion.Response.Challenge.String()))
+		assert.ErrorIs(t, err, ErrPasskeyRejected)
+		mockRepo.AssertNotCalled(t, "LookupUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("WrongChallenge", func(t *testing.T) {
+		mockRepo := new(repositories.MockPasskeyRepository)
+		service := newTestPasskeyService(t, mockRepo)
+		authenticator, stored := register(t, service, mockRepo)
+
+		_, state, err := service.BeginLogin(ctx)
+		require.NoError(t, err)
+		mockRepo.On("LookupUser", ctx, user.ID).Return(user, []pb.WebAuthnCredential{stored}, nil).Maybe()
+
+		_, err = service.FinishLogin(ctx, state, authenticator.get(t, b64.EncodeToString([]byte("stale-challenge"))))
+		assert.ErrorIs(t, err, ErrPasskeyRejected)
+		mockRepo.AssertNotCalled(t, "Impersonate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ExpiredState", func(t *testing.T) {
+		service := newTestPasskeyService(t, new(repositories.MockPasskeyRepository))
+		_, err := service.FinishLogin(ctx, "", "{}")
+		assert.ErrorIs(t, err, ErrPasskeyRejected)
+	})
+}
+
+func TestPasskeyService_FinishRegistrationOtherUser(t *testing.T) {
+	mockRepo := new(repositories.MockPasskeyRepository)
+	service := newTestPasskeyService(t, mockRepo)
+	ctx := context.Background()
+	owner := &pb.Client{AuthRecord: &pb.User{ID: "owner000000000a", Email: "owner@fleet.com"}}
+	intruder := &pb.Client{AuthRecord: &pb.User{ID: "intruder0000000", Email: "intruder@fleet.com"}}
+
+	mockRepo.On("Credentials", ctx, owner, "owner000000000a").Return([]pb.WebAuthnCredential{}, nil).Once()
+	creation, state, err := service.BeginRegistration(ctx, owner)
+	require.NoError(t, err)
+
+	response := newSoftAuthenticator(t).create(t, creation.Response.Challenge.String(), []byte("owner000000000a"))
+	err = service.FinishRegistration(ctx, intruder, state, response, "")
+	assert.ErrorIs(t, err, ErrPasskeyRejected)
+	mockRepo.AssertNotCalled(t, "AddCredential", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,47 +2,239 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/torresposso/gosmic/pb"
 	"github.com/torresposso/gosmic/repositories"
 )
 
 type PostService interface {
-	List(ctx context.Context, client *pb.Client, query string) ([]pb.Post, error)
+	List(ctx context.Context, client *pb.Client, query PostQuery) (*pb.ListResult[pb.Post], error)
 	Get(ctx context.Context, client *pb.Client, id string) (*pb.Post, error)
 	Create(ctx context.Context, client *pb.Client, title, content string, isPublic bool) error
 	Update(ctx context.Context, client *pb.Client, id string, title, content string, isPublic bool) error
 	Delete(ctx context.Context, client *pb.Client, id string) error
 	TogglePublic(ctx context.Context, client *pb.Client, id string) error
+	Subscribe(ctx context.Context, client *pb.Client) (<-chan pb.RealtimeEvent[pb.Post], error)
+}
+
+// Page sizes of the posts list.
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+// PostQuery selects one page of the posts a client may see.
+type PostQuery struct {
+	Search     string         // Search box query; see searchFilter for the syntax
+	Sort       PostSort       // Default: SortNewest
+	Visibility PostVisibility // Default: both public and private posts
+	From, To   time.Time      // Days the posts were created in, both inclusive; zero for open ends
+	Page       int            // 1-based page number. Default: 1
+	PerPage    int            // Posts per page. Default: DefaultPerPage, at most MaxPerPage
+	// After continues the list after a post already shown instead of skipping
+	// Page-1 pages, so posts added or removed meanwhile are neither repeated
+	// nor missed. Page is then only reported back. Optional
+	After PostCursor
+}
+
+// PostCursor is the position of a post in the list's order.
+type PostCursor struct {
+	Value string // The post's value of the sorted field
+	ID    string
+}
+
+// String encodes c for a query string; ParsePostCursor decodes it.
+func (c PostCursor) String() string {
+	if c.ID == "" {
+		return ""
+	}
+	// Record ids are alphanumeric, so the first colon ends the id
+	return c.ID + ":" + c.Value
+}
+
+// ParsePostCursor decodes a PostCursor.String, returning the zero cursor for
+// anything else.
+func ParsePostCursor(s string) PostCursor {
+	id, value, ok := strings.Cut(s, ":")
+	if !ok || id == "" {
+		return PostCursor{}
+	}
+	return PostCursor{Value: value, ID: id}
+}
+
+// CursorAfter returns the cursor continuing q's list after post.
+func (q PostQuery) CursorAfter(post pb.Post) PostCursor {
+	return q.order().cursor(post)
+}
+
+func (q PostQuery) order() postOrder {
+	if order, ok := postSorts[q.Sort]; ok {
+		return order
+	}
+	return postSorts[SortNewest]
+}
+
+// Newest reports whether q is the first page of all posts, newest first, the
+// only view a newly created post is known to belong at the top of.
+func (q PostQuery) Newest() bool {
+	return q.Search == "" && (q.Sort == "" || q.Sort == SortNewest) && q.Visibility == "" &&
+		q.From.IsZero() && q.To.IsZero() && q.Page <= 1
+}
+
+// PostSort is the order of the posts list.
+type PostSort string
+
+const (
+	SortNewest  PostSort = "newest"
+	SortOldest  PostSort = "oldest"
+	SortUpdated PostSort = "updated" // Recently updated first
+	SortTitle   PostSort = "title"   // Title A-Z
+)
+
+// postSorts maps each order to the field it sorts by; id breaks ties so pages
+// never overlap and every post has a distinct cursor.
+var postSorts = map[PostSort]postOrder{
+	SortNewest:  {field: pb.PostFieldCreated, desc: true},
+	SortOldest:  {field: pb.PostFieldCreated},
+	SortUpdated: {field: pb.PostFieldUpdated, desc: true},
+	SortTitle:   {field: pb.PostFieldTitle},
+}
+
+type postOrder struct {
+	field string
+	desc  bool
+}
+
+// sort returns the PocketBase sort, e.g. "-created,-id".
+func (o postOrder) sort() string {
+	if o.desc {
+		return "-" + o.field + ",-id"
+	}
+	return o.field + ",id"
+}
+
+// after filters the posts that come after cursor in this order.
+func (o postOrder) after(cursor PostCursor) string {
+	op := ">"
+	if o.desc {
+		op = "<"
+	}
+	expr := fmt.Sprintf("%[1]s %[2]s {:value} || (%[1]s = {:value} && id %[2]s {:id})", o.field, op)
+	return pb.Filter(expr, pb.Params{"value": cursor.Value, "id": cursor.ID})
+}
+
+func (o postOrder) cursor(post pb.Post) PostCursor {
+	var value string
+	switch o.field {
+	case pb.PostFieldCreated:
+		value = post.Created
+	case pb.PostFieldUpdated:
+		value = post.Updated
+	case pb.PostFieldTitle:
+		value = post.Title
+	}
+	return PostCursor{Value: value, ID: post.ID}
+}
+
+// Valid reports whether s is a supported order.
+func (s PostSort) Valid() bool {
+	_, ok := postSorts[s]
+	return ok
+}
+
+// PostVisibility restricts the posts list to public or private posts.
+type PostVisibility string
+
+const (
+	PublicOnly  PostVisibility = "public"
+	PrivateOnly PostVisibility = "private"
+)
+
+// Valid reports whether v is a supported restriction.
+func (v PostVisibility) Valid() bool {
+	return v == PublicOnly || v == PrivateOnly
+}
+
+// ErrUnverified is returned when an account whose email is not confirmed tries
+// something the VerificationPolicy reserves for verified accounts.
+var ErrUnverified = errors.New("confirm your email address before broadcasting publicly")
+
+// VerificationPolicy decides what accounts with an unconfirmed email may do.
+type VerificationPolicy struct {
+	// AllowPublicPosts lets unverified users publish posts publicly.
+	// Optional. Default: false
+	AllowPublicPosts bool
 }
 
 type postService struct {
-	repo repositories.PostRepository
+	repo   repositories.PostRepository
+	policy VerificationPolicy
 }
 
-func NewPostService(repo repositories.PostRepository) PostService {
-	return &postService{repo: repo}
+func NewPostService(repo repositories.PostRepository, policy VerificationPolicy) PostService {
+	return &postService{repo: repo, policy: policy}
 }
 
-func (s *postService) List(ctx context.Context, client *pb.Client, query string) ([]pb.Post, error) {
-	posts, err := s.repo.List(ctx, client)
-	if err != nil {
-		return nil, err
+// canPublish reports whether the client's user may make posts public.
+func (s *postService) canPublish(client *pb.Client) bool {
+	return s.policy.AllowPublicPosts || client.IsVerified()
+}
+
+// List returns the requested page of posts, searched, filtered and sorted by
+// PocketBase. Unsupported sorts and visibilities fall back to the defaults.
+// With query.After set the page starts after that post rather than at an offset.
+func (s *postService) List(ctx context.Context, client *pb.Client, query PostQuery) (*pb.ListResult[pb.Post], error) {
+	page, perPage := query.Page, query.PerPage
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = DefaultPerPage
+	}
+	perPage = min(perPage, MaxPerPage)
+
+	order := query.order()
+
+	filters := []string{searchFilter(query.Search)}
+	switch query.Visibility {
+	case PublicOnly:
+		filters = append(filters, pb.PostFilter.Public.Is(true))
+	case PrivateOnly:
+		filters = append(filters, pb.PostFilter.Public.Is(false))
+	}
+	if !query.From.IsZero() {
+		filters = append(filters, pb.PostFilter.Created.After(query.From))
+	}
+	if !query.To.IsZero() {
+		filters = append(filters, pb.PostFilter.Created.Before(query.To.AddDate(0, 0, 1)))
 	}
 
-	if query != "" {
-		filtered := []pb.Post{}
-		q := strings.ToLower(query)
-		for _, p := range posts {
-			if strings.Contains(strings.ToLower(p.Title), q) || strings.Contains(strings.ToLower(p.Content), q) {
-				filtered = append(filtered, p)
-			}
-		}
-		posts = filtered
+	if query.After.ID == "" {
+		return s.repo.List(ctx, client, pb.ListOptions{
+			Page:    page,
+			PerPage: perPage,
+			Filter:  pb.And(filters...),
+			Sort:    order.sort(),
+		})
 	}
 
-	return posts, nil
+	filters = append(filters, order.after(query.After))
+	result, err := s.repo.List(ctx, client, pb.ListOptions{
+		PerPage: perPage,
+		Filter:  pb.And(filters...),
+		Sort:    order.sort(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// PocketBase counts from the cursor; report the page it continues
+	result.Page = page
+	result.TotalPages += page - 1
+	return result, nil
 }
 
 func (s *postService) Get(ctx context.Context, client *pb.Client, id string) (*pb.Post, error) {
@@ -50,10 +242,17 @@ func (s *postService) Get(ctx context.Context, client *pb.Client, id string) (*p
 }
 
 func (s *postService) Create(ctx context.Context, client *pb.Client, title, content string, isPublic bool) error {
+	if isPublic && !s.canPublish(client) {
+		return ErrUnverified
+	}
 	return s.repo.Create(ctx, client, title, content, isPublic)
 }
 
 func (s *postService) Update(ctx context.Context, client *pb.Client, id string, title, content string, isPublic bool) error {
+	if isPublic && !s.canPublish(client) {
+		return ErrUnverified
+	}
+
 	data := map[string]any{
 		"title":   title,
 		"content": content,
@@ -67,5 +266,23 @@ func (s *postService) Delete(ctx context.Context, client *pb.Client, id string)
 }
 
 func (s *postService) TogglePublic(ctx context.Context, client *pb.Client, id string) error {
-	return s.repo.TogglePublic(ctx, client, id)
+	if s.canPublish(client) {
+		return s.repo.TogglePublic(ctx, client, id)
+	}
+
+	// Unverified users may still take a post private again. The post is read
+	// here anyway, so it is updated directly rather than read again by the
+	// repository's toggle.
+	post, err := s.repo.Get(ctx, client, id)
+	if err != nil {
+		return err
+	}
+	if !post.Public {
+		return ErrUnverified
+	}
+	return s.repo.Update(ctx, client, id, map[string]any{"public": false})
+}
+
+func (s *postService) Subscribe(ctx context.Context, client *pb.Client) (<-chan pb.RealtimeEvent[pb.Post], error) {
+	return s.repo.Subscribe(ctx, client)
 }
@@ -4,15 +4,17 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/torresposso/gosmic/pb"
 	"github.com/torresposso/gosmic/repositories"
 )
 
 func TestPostService_List(t *testing.T) {
 	mockRepo := new(repositories.MockPostRepository)
-	service := NewPostService(mockRepo)
+	service := NewPostService(mockRepo, VerificationPolicy{})
 	ctx := context.Background()
 	client := &pb.Client{}
 
@@ -20,32 +22,136 @@ func TestPostService_List(t *testing.T) {
 		{ID: "1", Title: "First Post", Content: "Hello world", Public: true},
 		{ID: "2", Title: "Secret Post", Content: "Classified info", Public: false},
 	}
+	const newest = "-created,-id"
 
 	t.Run("SuccessNoQuery", func(t *testing.T) {
-		mockRepo.On("List", ctx, client).Return(posts, nil).Once()
+		opts := pb.ListOptions{Page: 1, PerPage: DefaultPerPage, Sort: newest}
+		mockRepo.On("List", ctx, client, opts).Return(&pb.ListResult[pb.Post]{Items: posts}, nil).Once()
 
-		result, err := service.List(ctx, client, "")
+		result, err := service.List(ctx, client, PostQuery{})
 
 		assert.NoError(t, err)
-		assert.Len(t, result, 2)
+		assert.Len(t, result.Items, 2)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("SuccessWithQuery", func(t *testing.T) {
-		mockRepo.On("List", ctx, client).Return(posts, nil).Once()
+		filter := "(title ~ '%secret%') || (content ~ '%secret%')"
+		opts := pb.ListOptions{Page: 1, PerPage: DefaultPerPage, Filter: filter, Sort: newest}
+		mockRepo.On("List", ctx, client, opts).Return(&pb.ListResult[pb.Post]{Items: posts[1:]}, nil).Once()
 
-		result, err := service.List(ctx, client, "secret")
+		result, err := service.List(ctx, client, PostQuery{Search: "secret"})
 
 		assert.NoError(t, err)
-		assert.Len(t, result, 1)
-		assert.Equal(t, "2", result[0].ID)
+		assert.Len(t, result.Items, 1)
+		assert.Equal(t, "2", result.Items[0].ID)
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Pagination", func(t *testing.T) {
+		tests := []struct {
+			query         PostQuery
+			page, perPage int
+		}{
+			{PostQuery{Page: 3, PerPage: 5}, 3, 5},
+			{PostQuery{Page: -1, PerPage: -5}, 1, DefaultPerPage},
+			{PostQuery{Page: 2, PerPage: 1000}, 2, MaxPerPage},
+		}
+		for _, tt := range tests {
+			opts := pb.ListOptions{Page: tt.page, PerPage: tt.perPage, Sort: newest}
+			mockRepo.On("List", ctx, client, opts).Return(&pb.ListResult[pb.Post]{Page: tt.page, PerPage: tt.perPage}, nil).Once()
+
+			result, err := service.List(ctx, client, tt.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.page, result.Page)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("SortAndFilters", func(t *testing.T) {
+		day := func(d string) time.Time { t, _ := time.Parse(time.DateOnly, d); return t }
+		tests := []struct {
+			name   string
+			query  PostQuery
+			filter string
+			sort   string
+		}{
+			{"Oldest", PostQuery{Sort: SortOldest}, "", "created,id"},
+			{"Updated", PostQuery{Sort: SortUpdated}, "", "-updated,-id"},
+			{"Title", PostQuery{Sort: SortTitle}, "", "title,id"},
+			{"UnknownSort", PostQuery{Sort: "-password"}, "", newest},
+			{"PublicOnly", PostQuery{Visibility: PublicOnly}, "public = true", newest},
+			{"PrivateOnly", PostQuery{Visibility: PrivateOnly}, "public = false", newest},
+			{"UnknownVisibility", PostQuery{Visibility: "everyone"}, "", newest},
+			{"From", PostQuery{From: day("2026-01-01")}, "created >= '2026-01-01 00:00:00.000Z'", newest},
+			{"Range", PostQuery{From: day("2026-01-01"), To: day("2026-01-31")}, "(created >= '2026-01-01 00:00:00.000Z') && (created < '2026-02-01 00:00:00.000Z')", newest},
+			{"Combined", PostQuery{Search: "warp", Sort: SortTitle, Visibility: PrivateOnly},
+				"((title ~ '%warp%') || (content ~ '%warp%')) && (public = false)", "title,id"},
+		}
+		for _, tt := range tests {
+			opts := pb.ListOptions{Page: 1, PerPage: DefaultPerPage, Filter: tt.filter, Sort: tt.sort}
+			mockRepo.On("List", ctx, client, opts).Return(&pb.ListResult[pb.Post]{}, nil).Once()
+
+			_, err := service.List(ctx, client, tt.query)
+			assert.NoError(t, err, tt.name)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			query  PostQuery
+			filter string
+			sort   string
+		}{
+			{"Newest", PostQuery{Page: 2, After: PostCursor{Value: "2026-01-05 10:00:00.000Z", ID: "p9"}},
+				"created < '2026-01-05 10:00:00.000Z' || (created = '2026-01-05 10:00:00.000Z' && id < 'p9')", newest},
+			{"Title", PostQuery{Page: 2, Sort: SortTitle, After: PostCursor{Value: "Warp's end", ID: "p3"}},
+				`title > 'Warp\'s end' || (title = 'Warp\'s end' && id > 'p3')`, "title,id"},
+			{"WithFilters", PostQuery{Page: 3, Visibility: PublicOnly, After: PostCursor{Value: "2026-01-05 10:00:00.000Z", ID: "p9"}},
+				"(public = true) && (created < '2026-01-05 10:00:00.000Z' || (created = '2026-01-05 10:00:00.000Z' && id < 'p9'))", newest},
+		}
+		for _, tt := range tests {
+			opts := pb.ListOptions{PerPage: DefaultPerPage, Filter: tt.filter, Sort: tt.sort}
+			mockRepo.On("List", ctx, client, opts).Return(&pb.ListResult[pb.Post]{Page: 1, TotalPages: 2}, nil).Once()
+
+			result, err := service.List(ctx, client, tt.query)
+
+			assert.NoError(t, err, tt.name)
+			assert.Equal(t, tt.query.Page, result.Page, "%s: reports the page it continues", tt.name)
+			assert.Equal(t, tt.query.Page+1, result.TotalPages, tt.name)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("CursorRoundTrip", func(t *testing.T) {
+		post := pb.Post{ID: "p9", Title: "Warp: trial", Created: "2026-01-05 10:00:00.000Z", Updated: "2026-01-06 10:00:00.000Z"}
+		assert.Equal(t, PostCursor{Value: post.Created, ID: "p9"}, PostQuery{}.CursorAfter(post))
+		assert.Equal(t, PostCursor{Value: post.Updated, ID: "p9"}, PostQuery{Sort: SortUpdated}.CursorAfter(post))
+
+		cursor := PostQuery{Sort: SortTitle}.CursorAfter(post)
+		assert.Equal(t, cursor, ParsePostCursor(cursor.String()), "colons in the value survive")
+		assert.Equal(t, PostCursor{}, ParsePostCursor(""))
+		assert.Equal(t, PostCursor{}, ParsePostCursor("no-colon"))
+		assert.Empty(t, PostCursor{}.String())
+	})
+
+	t.Run("Newest", func(t *testing.T) {
+		assert.True(t, PostQuery{}.Newest())
+		assert.True(t, PostQuery{Sort: SortNewest, Page: 1, PerPage: 5}.Newest())
+		assert.False(t, PostQuery{Search: "warp"}.Newest())
+		assert.False(t, PostQuery{Sort: SortOldest}.Newest())
+		assert.False(t, PostQuery{Visibility: PublicOnly}.Newest())
+		assert.False(t, PostQuery{To: time.Now()}.Newest())
+		assert.False(t, PostQuery{Page: 2}.Newest())
+	})
+
 	t.Run("RepoError", func(t *testing.T) {
-		mockRepo.On("List", ctx, client).Return(nil, errors.New("list error")).Once()
+		mockRepo.On("List", ctx, client, mock.Anything).Return(nil, errors.New("list error")).Once()
 
-		result, err := service.List(ctx, client, "")
+		result, err := service.List(ctx, client, PostQuery{})
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -55,9 +161,9 @@ func TestPostService_List(t *testing.T) {
 
 func TestPostService_CRUD(t *testing.T) {
 	mockRepo := new(repositories.MockPostRepository)
-	service := NewPostService(mockRepo)
+	service := NewPostService(mockRepo, VerificationPolicy{})
 	ctx := context.Background()
-	client := &pb.Client{}
+	client := &pb.Client{AuthRecord: &pb.User{ID: "user-1", Verified: true}}
 
 	t.Run("GetSuccess", func(t *testing.T) {
 		expected := &pb.Post{ID: "1", Title: "Title"}
@@ -119,3 +225,46 @@ func TestPostService_CRUD(t *testing.T) {
 		assert.Equal(t, "toggle error", err.Error())
 	})
 }
+
+func TestPostService_VerificationPolicy(t *testing.T) {
+	ctx := context.Background()
+	client := &pb.Client{AuthRecord: &pb.User{ID: "user-1"}}
+
+	t.Run("UnverifiedCannotPublish", func(t *testing.T) {
+		mockRepo := new(repositories.MockPostRepository)
+		service := NewPostService(mockRepo, VerificationPolicy{})
+
+		assert.ErrorIs(t, service.Create(ctx, client, "New", "Content", true), ErrUnverified)
+		assert.ErrorIs(t, service.Update(ctx, client, "1", "Title", "Content", true), ErrUnverified)
+
+		mockRepo.On("Get", ctx, client, "1").Return(&pb.Post{ID: "1"}, nil).Once()
+		assert.ErrorIs(t, service.TogglePublic(ctx, client, "1"), ErrUnverified)
+
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "TogglePublic", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("UnverifiedKeepsPrivateAccess", func(t *testing.T) {
+		mockRepo := new(repositories.MockPostRepository)
+		service := NewPostService(mockRepo, VerificationPolicy{})
+
+		mockRepo.On("Create", ctx, client, "New", "Content", false).Return(nil).Once()
+		assert.NoError(t, service.Create(ctx, client, "New", "Content", false))
+
+		mockRepo.On("Get", ctx, client, "1").Return(&pb.Post{ID: "1", Public: true}, nil).Once()
+		mockRepo.On("Update", ctx, client, "1", map[string]any{"public": false}).Return(nil).Once()
+		assert.NoError(t, service.TogglePublic(ctx, client, "1"))
+
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "TogglePublic", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("PolicyAllowsPublic", func(t *testing.T) {
+		mockRepo := new(repositories.MockPostRepository)
+		service := NewPostService(mockRepo, VerificationPolicy{AllowPublicPosts: true})
+
+		mockRepo.On("Create", ctx, client, "New", "Content", true).Return(nil).Once()
+		assert.NoError(t, service.Create(ctx, client, "New", "Content", true))
+		mockRepo.AssertExpectations(t)
+	})
+}
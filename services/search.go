@@ -0,0 +1,167 @@
+package services
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/torresposso/gosmic/pb"
+)
+
+// searchFilter turns a search box query into a PocketBase filter on posts.
+// All terms must match; bare words and "quoted phrases" are looked up in the
+// title and content. The advanced syntax narrows the search:
+//
+//	title:warp          title contains "warp" (also title:"warp drive")
+//	content:nebula      content contains "nebula"
+//	public:true         only public (or, with false, private) posts
+//	created:2026-01-31  posts created that day (UTC)
+//	created:2026-01-01..2026-01-31, created:2026-01-01.., created:..2026-01-31
+//	                    posts created within the range, both ends inclusive
+//	updated:...         as created, for the last edit
+//
+// Anything that does not parse, such as "public:maybe", is searched as text.
+func searchFilter(query string) string {
+	var filters []string
+	for _, term := range splitTerms(query) {
+		t := parseTerm(term)
+		switch t.field {
+		case "":
+			filters = append(filters, t.filter)
+		case pb.PostFieldTitle:
+			filters = append(filters, pb.PostFilter.Title.Like(escapeLike(t.text)))
+		case pb.PostFieldContent:
+			filters = append(filters, pb.PostFilter.Content.Like(escapeLike(t.text)))
+		default:
+			filters = append(filters, pb.Or(pb.PostFilter.Title.Like(escapeLike(t.text)), pb.PostFilter.Content.Like(escapeLike(t.text))))
+		}
+	}
+	return pb.And(filters...)
+}
+
+// SearchTerms returns the text a search query looks for in post titles and
+// contents, for highlighting the matches.
+func SearchTerms(query string) (title, content []string) {
+	for _, term := range splitTerms(query) {
+		t := parseTerm(term)
+		if t.field == pb.PostFieldTitle || t.field == anyText {
+			title = append(title, t.text)
+		}
+		if t.field == pb.PostFieldContent || t.field == anyText {
+			content = append(content, t.text)
+		}
+	}
+	return title, content
+}
+
+// anyText marks a term searched in both the title and the content.
+const anyText = "*"
+
+// searchTerm is one parsed term of a search query: either text to look for
+// in field, or a ready-made filter when field is empty.
+type searchTerm struct {
+	field  string
+	text   string
+	filter string
+}
+
+func parseTerm(term string) searchTerm {
+	if key, value, ok := strings.Cut(term, ":"); ok && value != "" {
+		value = unquote(value)
+		switch strings.ToLower(key) {
+		case pb.PostFieldTitle:
+			return searchTerm{field: pb.PostFieldTitle, text: value}
+		case pb.PostFieldContent:
+			return searchTerm{field: pb.PostFieldContent, text: value}
+		case pb.PostFieldPublic:
+			switch strings.ToLower(value) {
+			case "true", "yes":
+				return searchTerm{filter: pb.PostFilter.Public.Is(true)}
+			case "false", "no":
+				return searchTerm{filter: pb.PostFilter.Public.Is(false)}
+			}
+		case pb.PostFieldCreated:
+			if filter, ok := dateRange(pb.PostFilter.Created, value); ok {
+				return searchTerm{filter: filter}
+			}
+		case pb.PostFieldUpdated:
+			if filter, ok := dateRange(pb.PostFilter.Updated, value); ok {
+				return searchTerm{filter: filter}
+			}
+		}
+	}
+	return searchTerm{field: anyText, text: unquote(term)}
+}
+
+// dateRange parses "day", "from..to", "from.." or "..to" with days as YYYY-MM-DD.
+func dateRange(field pb.DateField, value string) (string, bool) {
+	from, to, isRange := strings.Cut(value, "..")
+	if !isRange {
+		to = from
+	}
+
+	var filters []string
+	if from != "" {
+		start, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			return "", false
+		}
+		filters = append(filters, field.After(start))
+	}
+	if to != "" {
+		end, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			return "", false
+		}
+		filters = append(filters, field.Before(end.AddDate(0, 0, 1)))
+	}
+	if len(filters) == 0 {
+		return "", false
+	}
+	return pb.And(filters...), true
+}
+
+// splitTerms splits query on spaces outside double quotes.
+func splitTerms(query string) []string {
+	var terms []string
+	var term strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			term.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if term.Len() > 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(r)
+		}
+	}
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+
+	// Drop terms that were only quotes
+	kept := terms[:0]
+	for _, t := range terms {
+		if unquote(t) != "" {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+func unquote(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, `"`, ""))
+}
+
+// escapeLike builds a ~ pattern matching s literally anywhere in the value.
+// PocketBase only adds the surrounding % itself when the pattern has none and
+// otherwise treats % and _ as wildcards, so the pattern is wrapped here and
+// the wildcards and the \ escape character are escaped.
+func escapeLike(s string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s) + "%"
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/torresposso/gosmic/pb"
+	"github.com/torresposso/gosmic/pbtest"
+	"github.com/torresposso/gosmic/repositories"
+)
+
+func TestSearchFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"Empty", "", ""},
+		{"Blank", `   "" `, ""},
+		{"Word", "warp", "(title ~ '%warp%') || (content ~ '%warp%')"},
+		{"Words", "warp core", "((title ~ '%warp%') || (content ~ '%warp%')) && ((title ~ '%core%') || (content ~ '%core%'))"},
+		{"Phrase", `"warp core"`, "(title ~ '%warp core%') || (content ~ '%warp core%')"},
+		{"Title", "title:warp", "title ~ '%warp%'"},
+		{"TitlePhrase", `Title:"warp core"`, "title ~ '%warp core%'"},
+		{"Content", "content:nebula", "content ~ '%nebula%'"},
+		{"Public", "public:true", "public = true"},
+		{"Private", "public:no", "public = false"},
+		{"PublicInvalid", "public:maybe", "(title ~ '%public:maybe%') || (content ~ '%public:maybe%')"},
+		{"Day", "created:2026-01-31", "(created >= '2026-01-31 00:00:00.000Z') && (created < '2026-02-01 00:00:00.000Z')"},
+		{"Range", "created:2026-01-01..2026-01-31", "(created >= '2026-01-01 00:00:00.000Z') && (created < '2026-02-01 00:00:00.000Z')"},
+		{"From", "updated:2026-01-01..", "updated >= '2026-01-01 00:00:00.000Z'"},
+		{"Until", "created:..2026-01-31", "created < '2026-02-01 00:00:00.000Z'"},
+		{"BadDate", "created:yesterday", "(title ~ '%created:yesterday%') || (content ~ '%created:yesterday%')"},
+		{"EmptyRange", "created:..", "(title ~ '%created:..%') || (content ~ '%created:..%')"},
+		{"Combined", "title:log public:false", "(title ~ '%log%') && (public = false)"},
+		{"URL", "https://example.com", "(title ~ '%https://example.com%') || (content ~ '%https://example.com%')"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, searchFilter(tt.query))
+		})
+	}
+}
+
+func TestSearchFilterEscaping(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"Wildcards", "title:100%_done", `title ~ '%100\%\_done%'`},
+		{"Backslash", `title:C:\temp\`, `title ~ '%C:\\temp\\%'`},
+		{"Quote", "title:captain's", `title ~ '%captain\'s%'`},
+		// Each payload stays inside a single string literal
+		{"CloseString", "title:x'||public=false||'", `title ~ '%x\'||public=false||\'%'`},
+		{"EscapedQuote", `title:x\'||true||'`, `title ~ '%x\\\'||true||\'%'`},
+		{"DoubleQuotes", `"x" || author != ""`, `((title ~ '%x%') || (content ~ '%x%')) && ((title ~ '%||%') || (content ~ '%||%')) && ((title ~ '%author%') || (content ~ '%author%')) && ((title ~ '%!=%') || (content ~ '%!=%'))`},
+		{"Placeholder", "title:{:q}", `title ~ '%{:q}%'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, searchFilter(tt.query))
+		})
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	title, content := SearchTerms(`warp title:"core breach" content:nebula public:true created:2026-01-01.. public:maybe`)
+	assert.Equal(t, []string{"warp", "core breach", "public:maybe"}, title)
+	assert.Equal(t, []string{"warp", "nebula", "public:maybe"}, content)
+
+	title, content = SearchTerms("")
+	assert.Empty(t, title)
+	assert.Empty(t, content)
+}
+
+// TestSearchAgainstPocketBase runs searches through the real repository
+// against the fake PocketBase, so filters that fail to parse or escape their
+// string literal show up as errors or leaked posts.
+func TestSearchAgainstPocketBase(t *testing.T) {
+	server := pbtest.NewServer(t)
+	server.SeedFile(t, "../pbtest/testdata/fixtures.json")
+	server.AddPost(pb.Post{ID: "p5", Title: "100% shields_up", Content: `C:\logs\bridge`, Author: "spock", Public: true, Created: "2024-03-01 09:00:00.000Z"})
+	service := NewPostService(repositories.NewPostRepository(), VerificationPolicy{})
+	client := server.AuthClient("spock")
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"p1", "p3", "p4", "p5"}},
+		{"LOG", []string{"p3", "p4", "p5"}},
+		{"title:log", []string{"p4"}},
+		{`"shore leave"`, []string{"p3"}},
+		{"public:false", []string{"p4"}},
+		{"public:true log", []string{"p3", "p5"}},
+		{"created:2024-02-05", []string{"p3"}},
+		{"created:2024-02-01..", []string{"p3", "p4", "p5"}},
+		{"created:..2024-02-05", []string{"p1", "p3"}},
+		{"100%", []string{"p5"}},
+		{"%", []string{"p5"}},
+		{"_", []string{"p5"}},
+		{`\logs\`, []string{"p5"}},
+		// Injection attempts match nothing instead of widening the filter
+		{"x'||author!='", nil},
+		{`x\'||author!='`, nil},
+		{"title:x'||public=false||'", nil},
+		{`') || (author != '`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			result, err := service.List(context.Background(), client, PostQuery{Search: tt.query})
+			require.NoError(t, err)
+			var ids []string
+			for _, post := range result.Items {
+				ids = append(ids, post.ID)
+			}
+			assert.ElementsMatch(t, tt.want, ids)
+		})
+	}
+}
+
+func TestSortAndFilterAgainstPocketBase(t *testing.T) {
+	server := pbtest.NewServer(t)
+	server.SeedFile(t, "../pbtest/testdata/fixtures.json")
+	service := NewPostService(repositories.NewPostRepository(), VerificationPolicy{})
+	client := server.AuthClient("kirk")
+	day := func(d string) time.Time { t, _ := time.Parse(time.DateOnly, d); return t }
+
+	tests := []struct {
+		name  string
+		query PostQuery
+		want  []string
+	}{
+		{"Newest", PostQuery{}, []string{"p3", "p2", "p1"}},
+		{"Oldest", PostQuery{Sort: SortOldest}, []string{"p1", "p2", "p3"}},
+		{"Title", PostQuery{Sort: SortTitle}, []string{"p2", "p1", "p3"}},
+		{"PublicOnly", PostQuery{Visibility: PublicOnly}, []string{"p3", "p1"}},
+		{"PrivateOnly", PostQuery{Visibility: PrivateOnly}, []string{"p2"}},
+		{"Range", PostQuery{From: day("2024-01-10"), To: day("2024-01-20")}, []string{"p2", "p1"}},
+		{"SearchSorted", PostQuery{Search: "log", Sort: SortOldest}, []string{"p2", "p3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.List(context.Background(), client, tt.query)
+			require.NoError(t, err)
+			var ids []string
+			for _, post := range result.Items {
+				ids = append(ids, post.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+// TestCursorAgainstPocketBase pages through each order by cursor while a post
+// is added ahead of the reader, which offset pages would show twice.
+func TestCursorAgainstPocketBase(t *testing.T) {
+	for _, sort := range []PostSort{SortNewest, SortOldest, SortUpdated, SortTitle} {
+		t.Run(string(sort), func(t *testing.T) {
+			server := pbtest.NewServer(t)
+			server.SeedFile(t, "../pbtest/testdata/fixtures.json")
+			// Same title and timestamps as p1, so only the id breaks the tie
+			server.AddPost(pb.Post{ID: "p0", Title: "First contact", Author: "kirk", Public: true, Created: "2024-01-10 09:00:00.000Z"})
+			service := NewPostService(repositories.NewPostRepository(), VerificationPolicy{})
+			client := server.AuthClient("kirk")
+
+			all, err := service.List(context.Background(), client, PostQuery{Sort: sort})
+			require.NoError(t, err)
+			var want []string
+			for _, post := range all.Items {
+				want = append(want, post.ID)
+			}
+
+			query := PostQuery{Sort: sort, Page: 1, PerPage: 1}
+			var got []string
+			for {
+				result, err := service.List(context.Background(), client, query)
+				require.NoError(t, err)
+				require.LessOrEqual(t, len(result.Items), 1)
+				assert.Equal(t, query.Page, result.Page)
+				for _, post := range result.Items {
+					got = append(got, post.ID)
+				}
+				if !result.HasMore() {
+					break
+				}
+				if query.Page == 1 {
+					server.AddPost(pb.Post{ID: "p9", Title: "Aardvark", Author: "kirk", Created: "2024-03-01 09:00:00.000Z"})
+				}
+				query.After = query.CursorAfter(result.Items[0])
+				query.Page++
+			}
+			if sort == SortOldest {
+				// Added after the reader's position, so it comes at the end
+				want = append(want, "p9")
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Passkey (WebAuthn) ceremonies for the login and passkeys pages.
+// The server sends and expects binary fields as base64url strings.
+(function () {
+  if (window.gosmicPasskeys) return;
+  window.gosmicPasskeys = true;
+
+  function toBuffer(value) {
+    var base64 = value.replace(/-/g, "+").replace(/_/g, "/");
+    while (base64.length % 4) base64 += "=";
+    var binary = atob(base64);
+    var bytes = new Uint8Array(binary.length);
+    for (var i = 0; i < binary.length; i++) bytes[i] = binary.charCodeAt(i);
+    return bytes.buffer;
+  }
+
+  function toBase64URL(buffer) {
+    var bytes = new Uint8Array(buffer);
+    var binary = "";
+    for (var i = 0; i < bytes.length; i++) binary += String.fromCharCode(bytes[i]);
+    return btoa(binary).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "");
+  }
+
+  // post sends a form-encoded request so the CSRF middleware finds _csrf
+  function post(url, csrf, fields) {
+    var body = new URLSearchParams(fields || {});
+    body.set("_csrf", csrf);
+    return fetch(url, {
+      method: "POST",
+      body: body,
+      credentials: "same-origin",
+    }).then(function (resp) {
+      return resp.json().then(function (data) {
+        if (!resp.ok) throw new Error(data.error || "Request failed");
+        return data;
+      });
+    });
+  }
+
+  function showError(button, message) {
+    var el = button.parentElement.querySelector("[data-passkey-error]");
+    if (!el) return;
+    el.textContent = message;
+    el.classList.remove("hidden");
+  }
+
+  function login(button) {
+    var csrf = button.dataset.csrf;
+    return post("/login/passkey/begin", csrf)
+      .then(function (options) {
+        var publicKey = options.publicKey;
+        publicKey.challenge = toBuffer(publicKey.challenge);
+        (publicKey.allowCredentials || []).forEach(function (c) {
+          c.id = toBuffer(c.id);
+        });
+        return navigator.credentials.get({ publicKey: publicKey });
+      })
+      .then(function (credential) {
+        var response = credential.response;
+        return post("/login/passkey/finish", csrf, {
+          credential: JSON.stringify({
+            id: credential.id,
+            rawId: toBase64URL(credential.rawId),
+            type: credential.type,
+            response: {
+              authenticatorData: toBase64URL(response.authenticatorData),
+              clientDataJSON: toBase64URL(response.clientDataJSON),
+              signature: toBase64URL(response.signature),
+              userHandle: response.userHandle ? toBase64URL(response.userHandle) : null,
+            },
+          }),
+        });
+      });
+  }
+
+  function register(button) {
+    var csrf = button.dataset.csrf;
+    var nameInput = document.getElementById("passkey-name");
+    return post("/dashboard/passkeys/register/begin", csrf)
+      .then(function (options) {
+        var publicKey = options.publicKey;
+        publicKey.challenge = toBuffer(publicKey.challenge);
+        publicKey.user.id = toBuffer(publicKey.user.id);
+        (publicKey.excludeCredentials || []).forEach(function (c) {
+          c.id = toBuffer(c.id);
+        });
+        return navigator.credentials.create({ publicKey: publicKey });
+      })
+      .then(function (credential) {
+        var response = credential.response;
+        return post("/dashboard/passkeys/register/finish", csrf, {
+          name: nameInput ? nameInput.value : "",
+          credential: JSON.stringify({
+            id: credential.id,
+            rawId: toBase64URL(credential.rawId),
+            type: credential.type,
+            response: {
+              attestationObject: toBase64URL(response.attestationObject),
+              clientDataJSON: toBase64URL(response.clientDataJSON),
+              transports: response.getTransports ? response.getTransports() : [],
+            },
+          }),
+        });
+      });
+  }
+
+  document.addEventListener("click", function (event) {
+    var button = event.target.closest("[data-passkey-login], [data-passkey-register]");
+    if (!button) return;
+
+    if (!window.PublicKeyCredential) {
+      showError(button, "This browser does not support passkeys");
+      return;
+    }
+
+    var ceremony = button.hasAttribute("data-passkey-login") ? login : register;
+    button.disabled = true;
+    ceremony(button)
+      .then(function (result) {
+        window.location.assign(result.redirect);
+      })
+      .catch(function (err) {
+        if (err.name === "NotAllowedError") {
+          showError(button, "Passkey request was cancelled");
+        } else {
+          showError(button, err.message);
+        }
+      })
+      .finally(function () {
+        button.disabled = false;
+      });
+  });
+})();
@@ -65,4 +65,34 @@ func Error(message string, statusCode int) templ.Component {
 	})
 }
 
+// Unavailable is shown when PocketBase cannot be reached (connection failures or an open circuit breaker).
+func Unavailable() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var4 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var4 == nil {
+			templ_7745c5c3_Var4 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<div class=\"hero min-h-[70vh]\"><div class=\"hero-content text-center\"><div class=\"max-w-md\"><div class=\"text-9xl font-bold text-warning opacity-30 mb-4\">503</div><h1 class=\"text-4xl font-bold mb-4\"><span class=\"text-warning\" role=\"img\" aria-label=\"Satellite\">📡</span> Mission Control Unreachable</h1><p class=\"text-base-content/80 text-lg mb-8\" role=\"alert\">We lost contact with PocketBase. Your logs are safe; the link usually recovers within a few seconds.</p><div class=\"flex flex-col sm:flex-row gap-4 justify-center\"><button onclick=\"location.reload()\" class=\"btn btn-primary gap-2\">Retry Transmission</button> <a href=\"/\" class=\"btn btn-outline btn-primary gap-2\">Return to Base</a></div></div></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
 var _ = templruntime.GeneratedTemplate
@@ -47,7 +47,7 @@ func Index(isLoggedIn bool) templ.Component {
 	})
 }
 
-func Dashboard(userName string, userEmail string, postCount int, csrf string) templ.Component {
+func Dashboard(userName string, userEmail string, avatarURL string, verified bool, postCount int, csrf string) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -68,59 +68,131 @@ func Dashboard(userName string, userEmail string, postCount int, csrf string) te
 			templ_7745c5c3_Var2 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<!-- Dashboard Header --><div class=\"mb-8\"><h1 class=\"text-4xl font-bold mb-2\"><span class=\"text-primary\" role=\"img\" aria-label=\"Dashboard\">📊</span> Command Center</h1><p class=\"text-base-content/80 text-lg\">Welcome aboard, <span class=\"text-primary font-semibold\">Commander ")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<!-- Dashboard Header --><div class=\"mb-8 flex items-center gap-4\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var3 string
-		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(userName)
+		if avatarURL != "" {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<div class=\"avatar\"><div class=\"w-16 rounded-full ring-2 ring-primary ring-offset-2 ring-offset-base-100\"><img src=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var3 string
+			templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(avatarURL)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/home.templ`, Line: 104, Col: 25}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "\" alt=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var4 string
+			templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs("Avatar of " + userName)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/home.templ`, Line: 104, Col: 57}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "\"></div></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<div><h1 class=\"text-4xl font-bold mb-2\"><span class=\"text-primary\" role=\"img\" aria-label=\"Dashboard\">📊</span> Command Center</h1><p class=\"text-base-content/80 text-lg\">Welcome aboard, <span class=\"text-primary font-semibold\">Commander ")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var5 string
+		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(userName)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/home.templ`, Line: 113, Col: 81}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/home.templ`, Line: 105, Col: 80}
+			return templ_7745c5c3_Err
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "</span>!</p></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</span>!</p></div><!-- Stats Grid --><div class=\"grid grid-cols-1 md:grid-cols-3 gap-6 mb-8\"><div class=\"stat bg-base-200 rounded-box shadow\"><div class=\"stat-figure text-primary\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-8 w-8\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M9 12h6m-6 4h6m2 5H7a2 2 0 01-2-2V5a2 2 0 012-2h5.586a1 1 0 01.707.293l5.414 5.414a1 1 0 01.293.707V19a2 2 0 01-2 2z\"></path></svg></div><div class=\"stat-title\">Mission Logs</div><div class=\"stat-value text-primary\">")
+		if !verified {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "<div class=\"alert alert-warning mb-8\" role=\"status\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-6 w-6 shrink-0 stroke-current\" fill=\"none\" viewBox=\"0 0 24 24\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M3 8l7.89 5.26a2 2 0 002.22 0L21 8M5 19h14a2 2 0 002-2V7a2 2 0 00-2-2H5a2 2 0 00-2 2v10a2 2 0 002 2z\"></path></svg> <span>Comms ID unconfirmed. Open the verification link sent to ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var6 string
+			templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(userEmail)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/home.templ`, Line: 123, Col: 77}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, " to unlock public broadcasts.</span><form method=\"POST\" action=\"/dashboard/verify/resend\"><input type=\"hidden\" name=\"_csrf\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var7 string
+			templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(csrf)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/home.templ`, Line: 125, Col: 50}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "\"> <button type=\"submit\" class=\"btn btn-sm\">Resend Link</button></form></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "<!-- Stats Grid --><div class=\"grid grid-cols-1 md:grid-cols-3 gap-6 mb-8\"><div class=\"stat bg-base-200 rounded-box shadow\"><div class=\"stat-figure text-primary\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-8 w-8\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M9 12h6m-6 4h6m2 5H7a2 2 0 01-2-2V5a2 2 0 012-2h5.586a1 1 0 01.707.293l5.414 5.414a1 1 0 01.293.707V19a2 2 0 01-2 2z\"></path></svg></div><div class=\"stat-title\">Mission Logs</div><div class=\"stat-value text-primary\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var4 string
-		templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(strconv.Itoa(postCount))
+		var templ_7745c5c3_Var8 string
+		templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(strconv.Itoa(postCount))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/home.templ`, Line: 118, Col: 65}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/home.templ`, Line: 140, Col: 65}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</div><div class=\"stat-desc\">Total entries recorded</div></div><div class=\"stat bg-base-200 rounded-box shadow\"><div class=\"stat-figure text-primary\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-8 w-8\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M3 8l7.89 5.26a2 2 0 002.22 0L21 8M5 19h14a2 2 0 002-2V7a2 2 0 00-2-2H5a2 2 0 00-2 2v10a2 2 0 002 2z\"></path></svg></div><div class=\"stat-title\">Comms ID</div><div class=\"stat-value text-primary text-lg truncate\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "</div><div class=\"stat-desc\">Total entries recorded</div></div><div class=\"stat bg-base-200 rounded-box shadow\"><div class=\"stat-figure text-primary\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-8 w-8\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M3 8l7.89 5.26a2 2 0 002.22 0L21 8M5 19h14a2 2 0 002-2V7a2 2 0 00-2-2H5a2 2 0 00-2 2v10a2 2 0 002 2z\"></path></svg></div><div class=\"stat-title\">Comms ID</div><div class=\"stat-value text-primary text-lg truncate\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var5 string
-		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(userEmail)
+		var templ_7745c5c3_Var9 string
+		templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(userEmail)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/home.templ`, Line: 128, Col: 68}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/home.templ`, Line: 150, Col: 68}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</div><div class=\"stat-desc\">Registered frequency</div></div><div class=\"stat bg-base-200 rounded-box shadow\"><div class=\"stat-figure text-success\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-8 w-8\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M9 12l2 2 4-4m6 2a9 9 0 11-18 0 9 9 0 0118 0z\"></path></svg></div><div class=\"stat-title\">System Status</div><div class=\"stat-value text-success\">Online</div><div class=\"stat-desc\">All systems nominal</div></div></div><!-- Quick Actions Grid --><div class=\"grid grid-cols-1 lg:grid-cols-2 gap-6\"><!-- New Log Entry HUD --><div class=\"card bg-base-300/40 backdrop-blur-xl border border-primary/20 shadow-2xl relative overflow-hidden group/card transition-all duration-500 hover:border-primary/40\"><!-- Decorative HUD Accents --><div class=\"absolute top-0 left-0 w-8 h-8 border-t-2 border-l-2 border-primary/40\"></div><div class=\"absolute top-0 right-0 w-8 h-8 border-t-2 border-r-2 border-primary/40\"></div><div class=\"absolute bottom-0 left-0 w-8 h-8 border-b-2 border-l-2 border-primary/40\"></div><div class=\"absolute bottom-0 right-0 w-8 h-8 border-b-2 border-r-2 border-primary/40\"></div><div class=\"card-body relative z-10\"><div class=\"flex items-center justify-between mb-4\"><h2 class=\"card-title text-primary tracking-tighter flex items-center gap-3\"><span class=\"relative\"><span class=\"absolute inset-0 bg-primary/20 blur-lg animate-pulse\"></span> <svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-6 w-6 relative\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M11 5H6a2 2 0 00-2 2v11a2 2 0 002 2h11a2 2 0 002-2v-5m-1.414-9.414a2 2 0 112.828 2.828L11.828 15H9v-2.828l8.586-8.586z\"></path></svg></span> <span class=\"uppercase font-black text-xl italic underline decoration-primary/30 underline-offset-8\">New Mission Log</span></h2><div class=\"text-[10px] font-mono text-primary/60 flex flex-col items-end uppercase leading-tight\"><span>Terminal_ID: PB-G0-3</span> <span>Status: Ready_For_Input</span></div></div><!-- Search Form --><form method=\"GET\" action=\"/dashboard/posts\" class=\"mb-6 relative group\"><div class=\"join w-full bg-base-100/50 border border-primary/10 rounded-lg overflow-hidden transition-all duration-300 focus-within:border-primary/40\"><input type=\"search\" name=\"q\" placeholder=\"SCAN_EXISTING_DATA_LOGS...\" aria-label=\"Search existing posts\" class=\"input input-ghost join-item flex-1 font-mono text-xs focus:bg-transparent placeholder:text-primary/30\"> <button type=\"submit\" class=\"btn btn-primary btn-sm join-item h-auto min-h-full aspect-square border-none\" aria-label=\"Search\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-4 w-4\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M21 21l-6-6m2-5a7 7 0 11-14 0 7 7 0 0114 0z\"></path></svg></button></div></form><div class=\"divider before:bg-primary/5 after:bg-primary/5 m-0 opacity-50\"></div><!-- Create Post Form --><form method=\"POST\" action=\"/dashboard/posts\" class=\"space-y-5 pt-4\"><input type=\"hidden\" name=\"_csrf\" value=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "</div><div class=\"stat-desc\">Registered frequency</div></div><div class=\"stat bg-base-200 rounded-box shadow\"><div class=\"stat-figure text-success\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-8 w-8\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M9 12l2 2 4-4m6 2a9 9 0 11-18 0 9 9 0 0118 0z\"></path></svg></div><div class=\"stat-title\">System Status</div><div class=\"stat-value text-success\">Online</div><div class=\"stat-desc\">All systems nominal</div></div></div><!-- Quick Actions Grid --><div class=\"grid grid-cols-1 lg:grid-cols-2 gap-6\"><!-- New Log Entry HUD --><div class=\"card bg-base-300/40 backdrop-blur-xl border border-primary/20 shadow-2xl relative overflow-hidden group/card transition-all duration-500 hover:border-primary/40\"><!-- Decorative HUD Accents --><div class=\"absolute top-0 left-0 w-8 h-8 border-t-2 border-l-2 border-primary/40\"></div><div class=\"absolute top-0 right-0 w-8 h-8 border-t-2 border-r-2 border-primary/40\"></div><div class=\"absolute bottom-0 left-0 w-8 h-8 border-b-2 border-l-2 border-primary/40\"></div><div class=\"absolute bottom-0 right-0 w-8 h-8 border-b-2 border-r-2 border-primary/40\"></div><div class=\"card-body relative z-10\"><div class=\"flex items-center justify-between mb-4\"><h2 class=\"card-title text-primary tracking-tighter flex items-center gap-3\"><span class=\"relative\"><span class=\"absolute inset-0 bg-primary/20 blur-lg animate-pulse\"></span> <svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-6 w-6 relative\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M11 5H6a2 2 0 00-2 2v11a2 2 0 002 2h11a2 2 0 002-2v-5m-1.414-9.414a2 2 0 112.828 2.828L11.828 15H9v-2.828l8.586-8.586z\"></path></svg></span> <span class=\"uppercase font-black text-xl italic underline decoration-primary/30 underline-offset-8\">New Mission Log</span></h2><div class=\"text-[10px] font-mono text-primary/60 flex flex-col items-end uppercase leading-tight\"><span>Terminal_ID: PB-G0-3</span> <span>Status: Ready_For_Input</span></div></div><!-- Search Form --><form method=\"GET\" action=\"/dashboard/posts\" class=\"mb-6 relative group\"><div class=\"join w-full bg-base-100/50 border border-primary/10 rounded-lg overflow-hidden transition-all duration-300 focus-within:border-primary/40\"><input type=\"search\" name=\"q\" placeholder=\"SCAN_EXISTING_DATA_LOGS...\" aria-label=\"Search existing posts\" title=\"Filters: title:warp content:nebula public:true created:2026-01-01..2026-01-31\" class=\"input input-ghost join-item flex-1 font-mono text-xs focus:bg-transparent placeholder:text-primary/30\"> <button type=\"submit\" class=\"btn btn-primary btn-sm join-item h-auto min-h-full aspect-square border-none\" aria-label=\"Search\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-4 w-4\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M21 21l-6-6m2-5a7 7 0 11-14 0 7 7 0 0114 0z\"></path></svg></button></div></form><div class=\"divider before:bg-primary/5 after:bg-primary/5 m-0 opacity-50\"></div><!-- Create Post Form --><form method=\"POST\" action=\"/dashboard/posts\" class=\"space-y-5 pt-4\"><input type=\"hidden\" name=\"_csrf\" value=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var6 string
-		templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(csrf)
+		var templ_7745c5c3_Var10 string
+		templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(csrf)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/home.templ`, Line: 186, Col: 51}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/home.templ`, Line: 208, Col: 51}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "\"><div class=\"form-control\"><label class=\"label pt-0\" for=\"dashboard-title\"><span class=\"label-text font-black text-[10px] uppercase tracking-[0.2em] text-primary/80\">Identifier_Subject</span></label><div class=\"relative\"><div class=\"absolute inset-0 bg-primary/5 blur-md opacity-0 transition-opacity duration-500 peer-focus:opacity-100\"></div><input type=\"text\" id=\"dashboard-title\" name=\"title\" required placeholder=\"GOSMIC_LOG_ENTRY_NUMBER...\" class=\"peer input input-bordered w-full bg-base-200/50 border-primary/20 focus:border-primary/60 focus:bg-base-200 transition-all duration-300 font-mono text-primary placeholder:text-primary/30 uppercase text-sm tracking-wider\"></div></div><div class=\"form-control\"><label class=\"label pb-1\" for=\"dashboard-content\"><span class=\"label-text font-black text-[10px] uppercase tracking-[0.2em] text-primary/80\">Observation_Matrix</span></label> <textarea id=\"dashboard-content\" name=\"content\" placeholder=\"Awaiting commander input...\" class=\"textarea textarea-bordered h-40 bg-base-200/50 border-primary/20 focus:border-primary/60 focus:bg-base-200 transition-all duration-300 font-mono text-sm leading-relaxed text-primary/90 placeholder:text-primary/30\"></textarea></div><label for=\"dashboard-public\" class=\"flex items-center justify-between p-3 bg-primary/5 rounded border border-primary/10 hover:bg-primary/10 transition-colors duration-300 cursor-pointer\"><div class=\"flex flex-col\"><span class=\"text-[10px] font-black uppercase tracking-widest text-primary/80\">Deep Space Broadcast (Public)</span> <span class=\"text-[9px] font-mono text-primary/60\">Status: All_Frequencies_Reception</span></div><input type=\"checkbox\" id=\"dashboard-public\" name=\"public\" class=\"toggle toggle-primary toggle-xs md:toggle-sm border-primary/30\"></label> <button type=\"submit\" class=\"btn btn-primary w-full border-none shadow-[0_0_20px_-5px_rgba(var(--p),0.4)] hover:shadow-[0_0_30px_-5px_rgba(var(--p),0.6)] group overflow-hidden relative\"><div class=\"absolute inset-0 bg-[radial-gradient(circle_at_center,_var(--p)_0%,_transparent_70%)] opacity-20 group-hover:opacity-40 transition-opacity duration-300\"></div><span class=\"relative z-10 flex items-center justify-center gap-3 font-black tracking-[0.3em] text-sm italic group-hover:scale-105 transition-all duration-500\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-5 w-5 animate-pulse\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M12 19l9 2-9-18-9 18 9-2zm0 0v-8\"></path></svg> EXECUTE_TRANSMISSION</span></button></form></div></div><!-- Navigation Card --><div class=\"card bg-base-200 shadow-xl\"><div class=\"card-body\"><h2 class=\"card-title text-primary\"><span role=\"img\" aria-label=\"Link\">🔗</span> Navigation</h2><ul class=\"menu bg-base-100 rounded-box w-full\"><li><a href=\"/dashboard/posts\" class=\"flex gap-3\"><span class=\"text-xl\" role=\"img\" aria-label=\"Books\">📚</span> <span>Review All Logs</span></a></li><li><a href=\"/\" class=\"flex gap-3\"><span class=\"text-xl\" role=\"img\" aria-label=\"Home\">🏠</span> <span>Return to Base</span></a></li><li><a href=\"/logout\" class=\"flex gap-3 text-warning\"><span class=\"text-xl\" role=\"img\" aria-label=\"Door\">🚪</span> <span>Eject / Logout</span></a></li></ul></div></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "\"><div class=\"form-control\"><label class=\"label pt-0\" for=\"dashboard-title\"><span class=\"label-text font-black text-[10px] uppercase tracking-[0.2em] text-primary/80\">Identifier_Subject</span></label><div class=\"relative\"><div class=\"absolute inset-0 bg-primary/5 blur-md opacity-0 transition-opacity duration-500 peer-focus:opacity-100\"></div><input type=\"text\" id=\"dashboard-title\" name=\"title\" required placeholder=\"GOSMIC_LOG_ENTRY_NUMBER...\" class=\"peer input input-bordered w-full bg-base-200/50 border-primary/20 focus:border-primary/60 focus:bg-base-200 transition-all duration-300 font-mono text-primary placeholder:text-primary/30 uppercase text-sm tracking-wider\"></div></div><div class=\"form-control\"><label class=\"label pb-1\" for=\"dashboard-content\"><span class=\"label-text font-black text-[10px] uppercase tracking-[0.2em] text-primary/80\">Observation_Matrix</span></label> <textarea id=\"dashboard-content\" name=\"content\" placeholder=\"Awaiting commander input...\" class=\"textarea textarea-bordered h-40 bg-base-200/50 border-primary/20 focus:border-primary/60 focus:bg-base-200 transition-all duration-300 font-mono text-sm leading-relaxed text-primary/90 placeholder:text-primary/30\"></textarea></div><label for=\"dashboard-public\" class=\"flex items-center justify-between p-3 bg-primary/5 rounded border border-primary/10 hover:bg-primary/10 transition-colors duration-300 cursor-pointer\"><div class=\"flex flex-col\"><span class=\"text-[10px] font-black uppercase tracking-widest text-primary/80\">Deep Space Broadcast (Public)</span> <span class=\"text-[9px] font-mono text-primary/60\">Status: All_Frequencies_Reception</span></div><input type=\"checkbox\" id=\"dashboard-public\" name=\"public\" class=\"toggle toggle-primary toggle-xs md:toggle-sm border-primary/30\"></label> <button type=\"submit\" class=\"btn btn-primary w-full border-none shadow-[0_0_20px_-5px_rgba(var(--p),0.4)] hover:shadow-[0_0_30px_-5px_rgba(var(--p),0.6)] group overflow-hidden relative\"><div class=\"absolute inset-0 bg-[radial-gradient(circle_at_center,_var(--p)_0%,_transparent_70%)] opacity-20 group-hover:opacity-40 transition-opacity duration-300\"></div><span class=\"relative z-10 flex items-center justify-center gap-3 font-black tracking-[0.3em] text-sm italic group-hover:scale-105 transition-all duration-500\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-5 w-5 animate-pulse\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\" aria-hidden=\"true\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M12 19l9 2-9-18-9 18 9-2zm0 0v-8\"></path></svg> EXECUTE_TRANSMISSION</span></button></form></div></div><!-- Navigation Card --><div class=\"card bg-base-200 shadow-xl\"><div class=\"card-body\"><h2 class=\"card-title text-primary\"><span role=\"img\" aria-label=\"Link\">🔗</span> Navigation</h2><ul class=\"menu bg-base-100 rounded-box w-full\"><li><a href=\"/dashboard/posts\" class=\"flex gap-3\"><span class=\"text-xl\" role=\"img\" aria-label=\"Books\">📚</span> <span>Review All Logs</span></a></li><li><a href=\"/dashboard/passkeys\" class=\"flex gap-3\"><span class=\"text-xl\" role=\"img\" aria-label=\"Key\">🔑</span> <span>Manage Passkeys</span></a></li><li><a href=\"/\" class=\"flex gap-3\"><span class=\"text-xl\" role=\"img\" aria-label=\"Home\">🏠</span> <span>Return to Base</span></a></li><li><a href=\"/logout\" class=\"flex gap-3 text-warning\"><span class=\"text-xl\" role=\"img\" aria-label=\"Door\">🚪</span> <span>Eject / Logout</span></a></li></ul></div></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
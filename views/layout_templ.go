@@ -98,7 +98,7 @@ func Layout(title string, isLoggedIn bool, csrf string, flash string, flashType
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "</main><!-- Footer --><footer class=\"footer footer-center bg-base-200 text-base-content p-10 mt-auto\"><aside><p class=\"text-sm opacity-80\">Powered by <span class=\"text-primary font-semibold\">Fiber v3</span> + <span class=\"text-primary font-semibold\">PocketBase</span> + <span class=\"text-primary font-semibold\">Alpine.js</span></p><p class=\"text-xs opacity-70 mt-2\"><span role=\"img\" aria-label=\"Lightning bolt\">⚡</span> Warp Drive Active • Ad Astra Per Aspera</p></aside></footer><script src=\"/static/js/alpine.min.js\" defer></script><script src=\"/static/js/htmx.min.js\" defer></script></body></html>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "</main><!-- Footer --><footer class=\"footer footer-center bg-base-200 text-base-content p-10 mt-auto\"><aside><p class=\"text-sm opacity-80\">Powered by <span class=\"text-primary font-semibold\">Fiber v3</span> + <span class=\"text-primary font-semibold\">PocketBase</span> + <span class=\"text-primary font-semibold\">Alpine.js</span></p><p class=\"text-xs opacity-70 mt-2\"><span role=\"img\" aria-label=\"Lightning bolt\">⚡</span> Warp Drive Active • Ad Astra Per Aspera</p></aside></footer><script src=\"/static/js/alpine.min.js\" defer></script><script src=\"/static/js/htmx.min.js\" defer></script><script src=\"/static/js/htmx-ext-sse.js\" defer></script></body></html>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -140,7 +140,7 @@ func FlashMessage(flash string, flashType string) templ.Component {
 				var templ_7745c5c3_Var4 string
 				templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(flash)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/layout.templ`, Line: 117, Col: 15}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/layout.templ`, Line: 118, Col: 15}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 				if templ_7745c5c3_Err != nil {
@@ -158,7 +158,7 @@ func FlashMessage(flash string, flashType string) templ.Component {
 				var templ_7745c5c3_Var5 string
 				templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(flash)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/layout.templ`, Line: 132, Col: 15}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/layout.templ`, Line: 133, Col: 15}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
 				if templ_7745c5c3_Err != nil {
@@ -176,7 +176,7 @@ func FlashMessage(flash string, flashType string) templ.Component {
 				var templ_7745c5c3_Var6 string
 				templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(flash)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/layout.templ`, Line: 147, Col: 15}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/layout.templ`, Line: 148, Col: 15}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
 				if templ_7745c5c3_Err != nil {
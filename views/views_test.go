@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/torresposso/gosmic/pb"
 )
 
 func TestIndexView(t *testing.T) {
@@ -39,7 +40,7 @@ func TestDashboardView(t *testing.T) {
 	csrf := "fake-csrf-token"
 
 	buf := new(bytes.Buffer)
-	err := Dashboard(userName, userEmail, postCount, csrf).Render(context.Background(), buf)
+	err := Dashboard(userName, userEmail, "", true, postCount, csrf).Render(context.Background(), buf)
 	assert.NoError(t, err)
 
 	content := buf.String()
@@ -51,4 +52,76 @@ func TestDashboardView(t *testing.T) {
 	assert.Contains(t, content, csrf)
 	assert.Contains(t, content, "name=\"_csrf\"")
 	assert.Contains(t, content, "EXECUTE_TRANSMISSION") // Verify the new HTMX/styled button
+	assert.NotContains(t, content, "/dashboard/verify/resend")
+	assert.NotContains(t, content, "<img", "no avatar uploaded")
+}
+
+func TestDashboardViewAvatar(t *testing.T) {
+	buf := new(bytes.Buffer)
+	avatarURL := "http://127.0.0.1:8090/api/files/_pb_users_auth_/u1/shepard_x1y2z3.png"
+	err := Dashboard("Shepard", "shepard@normandy.sr2", avatarURL, true, 0, "fake-csrf-token").Render(context.Background(), buf)
+	assert.NoError(t, err)
+
+	content := buf.String()
+	assert.Contains(t, content, `src="`+avatarURL+`"`)
+	assert.Contains(t, content, `alt="Avatar of Shepard"`)
+}
+
+func TestDashboardViewUnverified(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := Dashboard("Ensign Kim", "kim@voyager.sf", "", false, 0, "fake-csrf-token").Render(context.Background(), buf)
+	assert.NoError(t, err)
+
+	content := buf.String()
+	assert.Contains(t, content, "Comms ID unconfirmed")
+	assert.Contains(t, content, "kim@voyager.sf")
+	assert.Contains(t, content, `action="/dashboard/verify/resend"`)
+}
+
+func TestPostsListHighlight(t *testing.T) {
+	page := PostsPage{
+		Posts: []pb.Post{
+			{ID: "1", Title: "Warp core breach", Content: "The **warp field** <script>collapsed</script> at WARP 9 [warp](https://example.com/warp)"},
+		},
+		Query:      "warp",
+		Page:       1,
+		TotalPages: 1,
+		Highlight:  Highlight{Title: []string{"warp"}, Content: []string{"warp", "field"}},
+	}
+
+	buf := new(bytes.Buffer)
+	err := PostsList(page, "fake-csrf-token").Render(context.Background(), buf)
+	assert.NoError(t, err)
+
+	content := buf.String()
+	assert.Contains(t, content, `id="posts-container"`)
+	assert.Contains(t, content, `sse-connect="/dashboard/posts/events?q=warp"`, "live updates follow the search")
+	assert.Contains(t, content, `>Warp</mark> core breach`)
+	assert.Contains(t, content, `>warp</mark>`)
+	assert.Contains(t, content, `>field</mark>`)
+	assert.Contains(t, content, `>WARP</mark> 9`)
+	assert.Contains(t, content, "<strong><mark>warp</mark> <mark>field</mark></strong>", "content is rendered from Markdown")
+	assert.Contains(t, content, `href="https://example.com/warp" rel="nofollow noreferrer"><mark>warp</mark></a>`, "only text is marked")
+	assert.NotContains(t, content, "<script", "raw HTML is stripped")
+}
+
+func TestHighlightSegments(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  []segment
+	}{
+		{"NoTerms", "Warp", nil, []segment{{Text: "Warp"}}},
+		{"EmptyTerm", "Warp", []string{""}, []segment{{Text: "Warp"}}},
+		{"CaseInsensitive", "Warp warp", []string{"WARP"}, []segment{{Text: "Warp", Match: true}, {Text: " "}, {Text: "warp", Match: true}}},
+		{"Longest", "warpdrive", []string{"warp", "warpdrive"}, []segment{{Text: "warpdrive", Match: true}}},
+		{"Unicode", "Ölkanne öl", []string{"Öl"}, []segment{{Text: "Öl", Match: true}, {Text: "kanne "}, {Text: "öl", Match: true}}},
+		{"Middle", "a%b", []string{"%"}, []segment{{Text: "a"}, {Text: "%", Match: true}, {Text: "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, highlightSegments(tt.text, tt.terms))
+		})
+	}
 }